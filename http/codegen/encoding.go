@@ -0,0 +1,109 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	httpdesign "goa.design/goa/http/design"
+)
+
+// EncodingData describes a single non-built-in encoder or decoder
+// registration, derived from a Consumes or Produces DSL call that passed a
+// Package option.
+type EncodingData struct {
+	// MIMETypes lists the media types the registration applies to.
+	MIMETypes []string
+	// PackagePath is the import path of the package implementing the
+	// codec.
+	PackagePath string
+	// Function is the name of the function used to instantiate the
+	// codec, defaulting to "NewDecoder" or "NewEncoder".
+	Function string
+}
+
+// BuildDecoderData returns the decoder registrations declared on root via a
+// Consumes call with a Package option. Registrations without a package are
+// skipped: RequestDecoder has "application/json" and "application/xml"
+// wired in and decodes every other media type it has no decoder for as
+// JSON.
+func BuildDecoderData(root *httpdesign.RootExpr) []*EncodingData {
+	var data []*EncodingData
+	for _, d := range root.Decoders {
+		if d.PackagePath == "" {
+			continue
+		}
+		fn := d.Function
+		if fn == "" {
+			fn = "NewDecoder"
+		}
+		data = append(data, &EncodingData{MIMETypes: d.MIMETypes, PackagePath: d.PackagePath, Function: fn})
+	}
+	return data
+}
+
+// BuildEncoderData returns the encoder registrations declared on root via a
+// Produces call with a Package option. Registrations without a package are
+// skipped: ResponseEncoder has "application/json", "application/xml" and
+// "application/x-ndjson" wired in and encodes every other media type it has
+// no encoder for as JSON.
+func BuildEncoderData(root *httpdesign.RootExpr) []*EncodingData {
+	var data []*EncodingData
+	for _, e := range root.Encoders {
+		if e.PackagePath == "" {
+			continue
+		}
+		fn := e.Function
+		if fn == "" {
+			fn = "NewEncoder"
+		}
+		data = append(data, &EncodingData{MIMETypes: e.MIMETypes, PackagePath: e.PackagePath, Function: fn})
+	}
+	return data
+}
+
+// GenerateEncoding renders the RegisterEncodings function that calls
+// http.RegisterDecoder/http.RegisterEncoder for every media type declared
+// via a Consumes/Produces call with a Package option, so the generated
+// server main can wire those packages in with a single call instead of
+// leaving the registration up to the user. It returns "" if root declares
+// no codec with a Package option.
+func GenerateEncoding(root *httpdesign.RootExpr) (string, error) {
+	decoders := BuildDecoderData(root)
+	encoders := BuildEncoderData(root)
+	if len(decoders) == 0 && len(encoders) == 0 {
+		return "", nil
+	}
+	return renderEncoding(decoders, encoders), nil
+}
+
+// renderEncoding does the actual source rendering for GenerateEncoding. It
+// is factored out so it can be exercised with hand-built EncodingData
+// without needing a full design tree.
+func renderEncoding(decoders, encoders []*EncodingData) string {
+	var b strings.Builder
+	b.WriteString("// RegisterEncodings registers the decoders and encoders for the non-built-in\n")
+	b.WriteString("// media types declared via Consumes and Produces.\n")
+	b.WriteString("func RegisterEncodings() {\n")
+	for _, d := range decoders {
+		alias := packageAlias(d.PackagePath)
+		for _, mt := range d.MIMETypes {
+			fmt.Fprintf(&b, "\tRegisterDecoder(%q, func(r io.Reader) Decoder { return %s.%s(r) })\n", mt, alias, d.Function)
+		}
+	}
+	for _, e := range encoders {
+		alias := packageAlias(e.PackagePath)
+		for _, mt := range e.MIMETypes {
+			fmt.Fprintf(&b, "\tRegisterEncoder(%q, func(w io.Writer) Encoder { return %s.%s(w) })\n", mt, alias, e.Function)
+		}
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// packageAlias derives the Go package identifier used to reference
+// packagePath from its last path segment, e.g.
+// "github.com/vmihailenco/msgpack" becomes "msgpack".
+func packageAlias(packagePath string) string {
+	parts := strings.Split(packagePath, "/")
+	return parts[len(parts)-1]
+}
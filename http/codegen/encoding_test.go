@@ -0,0 +1,64 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	httpdesign "goa.design/goa/http/design"
+)
+
+func TestRenderEncoding(t *testing.T) {
+	decoders := []*EncodingData{
+		{MIMETypes: []string{"application/msgpack"}, PackagePath: "github.com/vmihailenco/msgpack", Function: "NewDecoder"},
+	}
+	encoders := []*EncodingData{
+		{MIMETypes: []string{"application/msgpack"}, PackagePath: "github.com/vmihailenco/msgpack", Function: "NewEncoder"},
+	}
+
+	src := renderEncoding(decoders, encoders)
+
+	if !strings.Contains(src, "func RegisterEncodings() {") {
+		t.Fatalf("expected a RegisterEncodings function, got:\n%s", src)
+	}
+	if !strings.Contains(src, `RegisterDecoder("application/msgpack", func(r io.Reader) Decoder { return msgpack.NewDecoder(r) })`) {
+		t.Fatalf("expected a decoder registration for msgpack, got:\n%s", src)
+	}
+	if !strings.Contains(src, `RegisterEncoder("application/msgpack", func(w io.Writer) Encoder { return msgpack.NewEncoder(w) })`) {
+		t.Fatalf("expected an encoder registration for msgpack, got:\n%s", src)
+	}
+}
+
+func TestBuildDecoderDataSkipsBuiltins(t *testing.T) {
+	root := &httpdesign.RootExpr{
+		Decoders: []*httpdesign.DecoderExpr{
+			{MIMETypes: []string{"application/json"}},
+			{MIMETypes: []string{"application/msgpack"}, PackagePath: "github.com/vmihailenco/msgpack"},
+		},
+	}
+
+	data := BuildDecoderData(root)
+
+	if len(data) != 1 {
+		t.Fatalf("expected only the non-built-in decoder to be returned, got %d entries", len(data))
+	}
+	if data[0].PackagePath != "github.com/vmihailenco/msgpack" {
+		t.Fatalf("expected the msgpack decoder, got %+v", data[0])
+	}
+	if data[0].Function != "NewDecoder" {
+		t.Fatalf("expected the Function field to default to NewDecoder, got %q", data[0].Function)
+	}
+}
+
+func TestGenerateEncodingEmptyWhenAllBuiltin(t *testing.T) {
+	root := &httpdesign.RootExpr{
+		Decoders: []*httpdesign.DecoderExpr{{MIMETypes: []string{"application/json"}}},
+	}
+
+	src, err := GenerateEncoding(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if src != "" {
+		t.Fatalf("expected no generated source when every codec is built-in, got:\n%s", src)
+	}
+}
@@ -0,0 +1,67 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderFileServer(t *testing.T) {
+	d := &FileServerData{
+		RequestPath:            "/static/",
+		FilePath:               "public",
+		ETag:                   "strong",
+		Ranges:                 true,
+		PrecompressedEncodings: []string{"gzip", "br"},
+		SPAFallback:            "index.html",
+		CacheControl:           "public, max-age=3600",
+		IndexNames:             []string{"index.html"},
+	}
+
+	src := renderFileServer(d)
+
+	if !strings.Contains(src, "func NewStaticFileServer() http.Handler {") {
+		t.Fatalf("expected a file server constructor for /static/, got:\n%s", src)
+	}
+	if !strings.Contains(src, `fsys := os.DirFS("public")`) {
+		t.Fatalf("expected the handler to serve from the OS file system, got:\n%s", src)
+	}
+	if !strings.Contains(src, `ETag:                    "strong",`) {
+		t.Fatalf("expected the strong ETag mode to be wired into the config, got:\n%s", src)
+	}
+	if !strings.Contains(src, `SPAFallback:             "index.html",`) {
+		t.Fatalf("expected the SPA fallback to be wired into the config, got:\n%s", src)
+	}
+	if !strings.Contains(src, `PrecompressedEncodings:  []string{"gzip", "br"},`) {
+		t.Fatalf("expected the precompressed encodings to be wired into the config, got:\n%s", src)
+	}
+}
+
+func TestRenderFileServerEmbed(t *testing.T) {
+	d := &FileServerData{
+		RequestPath: "/assets/",
+		FilePath:    "assets",
+		Embed:       true,
+	}
+
+	src := renderFileServer(d)
+
+	if !strings.Contains(src, "//go:embed assets") {
+		t.Fatalf("expected a go:embed directive, got:\n%s", src)
+	}
+	if !strings.Contains(src, "var AssetsAssets embed.FS") {
+		t.Fatalf("expected an embed.FS variable, got:\n%s", src)
+	}
+	if !strings.Contains(src, "fs.Sub(AssetsAssets, \"assets\")") {
+		t.Fatalf("expected the handler to build a sub fs.FS from the embedded assets, got:\n%s", src)
+	}
+}
+
+func TestGenerateFileServerNilReturnsEmpty(t *testing.T) {
+	src, err := GenerateFileServer(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if src != "" {
+		t.Fatalf("expected an empty string for a nil FileServerExpr, got:\n%s", src)
+	}
+}
@@ -0,0 +1,28 @@
+package v3
+
+import "testing"
+
+func TestImportIsDeterministic(t *testing.T) {
+	doc := &Document{
+		Info: &Info{Title: "test"},
+		Paths: map[string]*PathItem{
+			"/widgets": {
+				Get:  &Operation{OperationID: "widgets#list", Tags: []string{"widgets"}, Responses: map[string]*Response{"200": {Description: "OK"}}},
+				Post: &Operation{OperationID: "widgets#create", Tags: []string{"widgets"}, Responses: map[string]*Response{"200": {Description: "OK"}}},
+			},
+		},
+	}
+	first, err := Import(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		got, err := Import(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != first {
+			t.Fatalf("Import produced different output across runs:\n--- run 0 ---\n%s\n--- run %d ---\n%s", first, i+1, got)
+		}
+	}
+}
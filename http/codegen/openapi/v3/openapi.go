@@ -0,0 +1,331 @@
+// Package v3 generates an OpenAPI 3.1 document from a goa HTTP design, as an
+// alternative to the Swagger 2.0 output produced by the v2 package.
+package v3
+
+import (
+	"fmt"
+
+	"goa.design/goa/design"
+	httpdesign "goa.design/goa/http/design"
+)
+
+type (
+	// Document is the root of an OpenAPI 3.1 document, see
+	// https://spec.openapis.org/oas/v3.1.0.
+	Document struct {
+		OpenAPI    string                `json:"openapi" yaml:"openapi"`
+		Info       *Info                 `json:"info" yaml:"info"`
+		Servers    []*Server             `json:"servers,omitempty" yaml:"servers,omitempty"`
+		Paths      map[string]*PathItem  `json:"paths" yaml:"paths"`
+		Components *Components           `json:"components,omitempty" yaml:"components,omitempty"`
+	}
+
+	// Info corresponds to the OpenAPI "Info Object".
+	Info struct {
+		Title       string `json:"title" yaml:"title"`
+		Description string `json:"description,omitempty" yaml:"description,omitempty"`
+		Version     string `json:"version" yaml:"version"`
+	}
+
+	// Server corresponds to the OpenAPI "Server Object".
+	Server struct {
+		URL         string `json:"url" yaml:"url"`
+		Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	}
+
+	// PathItem corresponds to the OpenAPI "Path Item Object".
+	PathItem struct {
+		Get    *Operation `json:"get,omitempty" yaml:"get,omitempty"`
+		Post   *Operation `json:"post,omitempty" yaml:"post,omitempty"`
+		Put    *Operation `json:"put,omitempty" yaml:"put,omitempty"`
+		Patch  *Operation `json:"patch,omitempty" yaml:"patch,omitempty"`
+		Delete *Operation `json:"delete,omitempty" yaml:"delete,omitempty"`
+	}
+
+	// Operation corresponds to the OpenAPI "Operation Object".
+	//
+	// The generator does not yet emit "callbacks": that requires a
+	// concept of webhook endpoint that does not exist anywhere in the
+	// design tree this package walks, so there is nothing to translate
+	// it from. Add a Callbacks field here once the design package grows
+	// that concept instead of populating it with a guess.
+	Operation struct {
+		OperationID string                `json:"operationId" yaml:"operationId"`
+		Summary     string                `json:"summary,omitempty" yaml:"summary,omitempty"`
+		Tags        []string              `json:"tags,omitempty" yaml:"tags,omitempty"`
+		Parameters  []*Parameter          `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+		RequestBody *RequestBody          `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+		Responses   map[string]*Response  `json:"responses" yaml:"responses"`
+		Security    []map[string][]string `json:"security,omitempty" yaml:"security,omitempty"`
+	}
+
+	// Parameter corresponds to the OpenAPI "Parameter Object".
+	Parameter struct {
+		Name     string  `json:"name" yaml:"name"`
+		In       string  `json:"in" yaml:"in"`
+		Required bool    `json:"required,omitempty" yaml:"required,omitempty"`
+		Schema   *Schema `json:"schema,omitempty" yaml:"schema,omitempty"`
+	}
+
+	// RequestBody corresponds to the OpenAPI "Request Body Object". Using
+	// a map keyed by media type lets a single operation accept the
+	// several encodings registered via the Consumes DSL.
+	RequestBody struct {
+		Required bool                  `json:"required,omitempty" yaml:"required,omitempty"`
+		Content  map[string]*MediaType `json:"content" yaml:"content"`
+	}
+
+	// Response corresponds to the OpenAPI "Response Object".
+	Response struct {
+		Description string                `json:"description" yaml:"description"`
+		Content     map[string]*MediaType `json:"content,omitempty" yaml:"content,omitempty"`
+	}
+
+	// MediaType corresponds to the OpenAPI "Media Type Object".
+	MediaType struct {
+		Schema *Schema `json:"schema,omitempty" yaml:"schema,omitempty"`
+	}
+
+	// Components corresponds to the OpenAPI "Components Object".
+	Components struct {
+		Schemas         map[string]*Schema         `json:"schemas,omitempty" yaml:"schemas,omitempty"`
+		SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty" yaml:"securitySchemes,omitempty"`
+	}
+
+	// Schema corresponds to the (JSON Schema compatible) OpenAPI "Schema
+	// Object".
+	//
+	// There is no OneOf/AnyOf field: the design package this generator
+	// walks has no union/sum type to translate into one, only Object,
+	// Array and primitive attributes (see schemaFor). Add those fields
+	// back if and when the design package gains a union type.
+	Schema struct {
+		Type       string             `json:"type,omitempty" yaml:"type,omitempty"`
+		Format     string             `json:"format,omitempty" yaml:"format,omitempty"`
+		Items      *Schema            `json:"items,omitempty" yaml:"items,omitempty"`
+		Properties map[string]*Schema `json:"properties,omitempty" yaml:"properties,omitempty"`
+		Required   []string           `json:"required,omitempty" yaml:"required,omitempty"`
+		Ref        string             `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	}
+
+	// SecurityScheme corresponds to the OpenAPI "Security Scheme Object".
+	SecurityScheme struct {
+		Type             string                 `json:"type" yaml:"type"`
+		Scheme           string                 `json:"scheme,omitempty" yaml:"scheme,omitempty"`
+		In               string                 `json:"in,omitempty" yaml:"in,omitempty"`
+		Name             string                 `json:"name,omitempty" yaml:"name,omitempty"`
+		BearerFormat     string                 `json:"bearerFormat,omitempty" yaml:"bearerFormat,omitempty"`
+		Flows            map[string]*OAuth2Flow `json:"flows,omitempty" yaml:"flows,omitempty"`
+	}
+
+	// OAuth2Flow corresponds to one entry of the OpenAPI
+	// "OAuth Flows Object".
+	OAuth2Flow struct {
+		AuthorizationURL string            `json:"authorizationUrl,omitempty" yaml:"authorizationUrl,omitempty"`
+		TokenURL         string            `json:"tokenUrl,omitempty" yaml:"tokenUrl,omitempty"`
+		RefreshURL       string            `json:"refreshUrl,omitempty" yaml:"refreshUrl,omitempty"`
+		Scopes           map[string]string `json:"scopes" yaml:"scopes"`
+	}
+)
+
+// New builds the OpenAPI 3.1 document describing root.
+func New(root *httpdesign.RootExpr) (*Document, error) {
+	doc := &Document{
+		OpenAPI: "3.1.0",
+		Info: &Info{
+			Title:   root.Design.API.Name,
+			Version: "1.0",
+		},
+		Paths:      map[string]*PathItem{},
+		Components: &Components{Schemas: map[string]*Schema{}, SecuritySchemes: map[string]*SecurityScheme{}},
+	}
+	for _, s := range root.Design.API.Servers {
+		doc.Servers = append(doc.Servers, &Server{URL: s.URL})
+	}
+	for _, scheme := range root.SecuritySchemes {
+		ss, err := securitySchemeFor(scheme)
+		if err != nil {
+			return nil, err
+		}
+		doc.Components.SecuritySchemes[scheme.SchemeName] = ss
+	}
+	for _, svc := range root.HTTPServices {
+		for _, e := range svc.HTTPEndpoints {
+			if err := addOperation(root, doc, svc, e); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return doc, nil
+}
+
+// addOperation adds the path item and operation describing e to doc,
+// including one requestBody media type per decoder registered via Consumes
+// and a response built from the method result.
+func addOperation(root *httpdesign.RootExpr, doc *Document, svc *httpdesign.ServiceExpr, e *httpdesign.EndpointExpr) error {
+	path := e.RequestPath()
+	item, ok := doc.Paths[path]
+	if !ok {
+		item = &PathItem{}
+		doc.Paths[path] = item
+	}
+	opID := fmt.Sprintf("%s#%s", svc.Name(), e.Name())
+	op := &Operation{
+		OperationID: opID,
+		Tags:        []string{svc.Name()},
+		Responses:   map[string]*Response{"200": {Description: "OK"}},
+	}
+	for _, wc := range httpdesign.ExtractWildcards(path) {
+		op.Parameters = append(op.Parameters, &Parameter{Name: wc, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+	}
+	if m := e.MethodExpr; m != nil {
+		if m.Payload != nil {
+			op.RequestBody = requestBodyFor(doc, root, opID, m.Payload)
+		}
+		if m.Result != nil {
+			if s := registerSchema(doc, opID+"Response", m.Result); s != nil {
+				op.Responses["200"].Content = map[string]*MediaType{
+					"application/json": {Schema: s},
+				}
+			}
+		}
+	}
+	if len(e.Security) > 0 {
+		op.Security = make([]map[string][]string, len(e.Security))
+		for i, req := range e.Security {
+			entry := map[string][]string{}
+			for _, s := range req.Schemes {
+				entry[s.SchemeName] = req.Scopes
+			}
+			op.Security[i] = entry
+		}
+	}
+	switch e.Method() {
+	case "GET":
+		item.Get = op
+	case "POST":
+		item.Post = op
+	case "PUT":
+		item.Put = op
+	case "PATCH":
+		item.Patch = op
+	case "DELETE":
+		item.Delete = op
+	default:
+		return fmt.Errorf("openapi: unsupported HTTP method %q for endpoint %s", e.Method(), e.EvalName())
+	}
+	return nil
+}
+
+// requestBodyFor builds the requestBody of an operation from its method
+// payload, with one content entry per media type registered via Consumes
+// (defaulting to "application/json" when the API registered none) so
+// clients can post the payload encoded any of the ways the server accepts.
+func requestBodyFor(doc *Document, root *httpdesign.RootExpr, opID string, payload *design.AttributeExpr) *RequestBody {
+	schema := registerSchema(doc, opID+"Request", payload)
+	if schema == nil {
+		return nil
+	}
+	mimeTypes := root.Consumes
+	if len(mimeTypes) == 0 {
+		mimeTypes = []string{"application/json"}
+	}
+	content := make(map[string]*MediaType, len(mimeTypes))
+	for _, mt := range mimeTypes {
+		content[mt] = &MediaType{Schema: schema}
+	}
+	return &RequestBody{Required: true, Content: content}
+}
+
+// registerSchema computes the schema for attr, stores it in
+// doc.Components.Schemas under name and returns a $ref pointing to it. It
+// returns nil if attr is nil.
+func registerSchema(doc *Document, name string, attr *design.AttributeExpr) *Schema {
+	if attr == nil {
+		return nil
+	}
+	doc.Components.Schemas[name] = schemaFor(attr)
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// schemaFor translates a design.AttributeExpr into its OpenAPI Schema
+// equivalent, recursing into object properties and array items. Attribute
+// types this generator doesn't recognize fall back to an untyped schema
+// rather than failing the whole document.
+func schemaFor(attr *design.AttributeExpr) *Schema {
+	if obj := design.AsObject(attr.Type); obj != nil {
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		for _, nat := range obj {
+			s.Properties[nat.Name] = schemaFor(nat.Attribute)
+		}
+		if attr.Validation != nil {
+			s.Required = attr.Validation.Required
+		}
+		return s
+	}
+	if arr, ok := attr.Type.(*design.Array); ok {
+		return &Schema{Type: "array", Items: schemaFor(arr.ElemType)}
+	}
+	if s, ok := primitiveSchema(attr.Type); ok {
+		return s
+	}
+	return &Schema{}
+}
+
+// primitiveSchema maps a design.Primitive to its OpenAPI/JSON Schema
+// "type"/"format" pair.
+func primitiveSchema(t design.DataType) (*Schema, bool) {
+	switch t {
+	case httpdesign.Boolean:
+		return &Schema{Type: "boolean"}, true
+	case httpdesign.Int, httpdesign.Int32:
+		return &Schema{Type: "integer", Format: "int32"}, true
+	case httpdesign.Int64, httpdesign.UInt, httpdesign.UInt32, httpdesign.UInt64:
+		return &Schema{Type: "integer", Format: "int64"}, true
+	case httpdesign.Float32:
+		return &Schema{Type: "number", Format: "float"}, true
+	case httpdesign.Float64:
+		return &Schema{Type: "number", Format: "double"}, true
+	case httpdesign.String:
+		return &Schema{Type: "string"}, true
+	case httpdesign.Bytes:
+		return &Schema{Type: "string", Format: "binary"}, true
+	case httpdesign.Any:
+		return &Schema{}, true
+	default:
+		return nil, false
+	}
+}
+
+// securitySchemeFor translates a design.SecuritySchemeExpr into its OpenAPI
+// 3 equivalent.
+func securitySchemeFor(s *httpdesign.SecuritySchemeExpr) (*SecurityScheme, error) {
+	switch s.Kind {
+	case httpdesign.APIKeyKind:
+		return &SecurityScheme{Type: "apiKey", In: s.In, Name: s.Name}, nil
+	case httpdesign.BasicAuthKind:
+		return &SecurityScheme{Type: "http", Scheme: "basic"}, nil
+	case httpdesign.JWTKind:
+		return &SecurityScheme{Type: "http", Scheme: "bearer", BearerFormat: "JWT"}, nil
+	case httpdesign.OAuth2Kind:
+		flows := make(map[string]*OAuth2Flow, len(s.Flows))
+		for _, f := range s.Flows {
+			key := f.Kind
+			switch f.Kind {
+			case "authorization_code":
+				key = "authorizationCode"
+			case "client_credentials":
+				key = "clientCredentials"
+			}
+			flows[key] = &OAuth2Flow{
+				AuthorizationURL: f.AuthorizationURL,
+				TokenURL:         f.TokenURL,
+				RefreshURL:       f.RefreshURL,
+				Scopes:           s.Scopes,
+			}
+		}
+		return &SecurityScheme{Type: "oauth2", Flows: flows}, nil
+	default:
+		return nil, fmt.Errorf("openapi: unknown security scheme kind for %s", s.EvalName())
+	}
+}
@@ -0,0 +1,62 @@
+package v3
+
+import (
+	"testing"
+
+	"goa.design/goa/design"
+)
+
+func TestSchemaForObject(t *testing.T) {
+	attr := &design.AttributeExpr{
+		Type: &design.Object{
+			{Name: "id", Attribute: &design.AttributeExpr{Type: design.Int}},
+			{Name: "name", Attribute: &design.AttributeExpr{Type: design.String}},
+		},
+		Validation: &design.ValidationExpr{Required: []string{"id"}},
+	}
+
+	s := schemaFor(attr)
+
+	if s.Type != "object" {
+		t.Fatalf("got Type %q, want %q", s.Type, "object")
+	}
+	if len(s.Properties) != 2 {
+		t.Fatalf("got %d properties, want 2", len(s.Properties))
+	}
+	if s.Properties["id"].Type != "integer" {
+		t.Fatalf("got id Type %q, want %q", s.Properties["id"].Type, "integer")
+	}
+	if s.Properties["name"].Type != "string" {
+		t.Fatalf("got name Type %q, want %q", s.Properties["name"].Type, "string")
+	}
+	if len(s.Required) != 1 || s.Required[0] != "id" {
+		t.Fatalf("got Required %v, want [id]", s.Required)
+	}
+}
+
+func TestSchemaForArray(t *testing.T) {
+	attr := &design.AttributeExpr{
+		Type: &design.Array{ElemType: &design.AttributeExpr{Type: design.String}},
+	}
+	s := schemaFor(attr)
+	if s.Type != "array" {
+		t.Fatalf("got Type %q, want %q", s.Type, "array")
+	}
+	if s.Items == nil || s.Items.Type != "string" {
+		t.Fatalf("got Items %+v, want {Type: string}", s.Items)
+	}
+}
+
+func TestRegisterSchemaStoresComponent(t *testing.T) {
+	doc := &Document{Components: &Components{Schemas: map[string]*Schema{}}}
+	attr := &design.AttributeExpr{Type: design.String}
+
+	ref := registerSchema(doc, "Widget", attr)
+
+	if ref.Ref != "#/components/schemas/Widget" {
+		t.Fatalf("got ref %q, want %q", ref.Ref, "#/components/schemas/Widget")
+	}
+	if doc.Components.Schemas["Widget"].Type != "string" {
+		t.Fatalf("expected Widget schema to be registered with Type \"string\"")
+	}
+}
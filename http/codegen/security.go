@@ -0,0 +1,143 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	httpdesign "goa.design/goa/http/design"
+)
+
+// SecurityChainData describes the information needed to render an
+// endpoint's security chain: the distinct http.Authenticator parameters the
+// generated functions accept, and, for each alternative SecurityExpr
+// attached to the endpoint, the parameters to apply in order.
+type SecurityChainData struct {
+	// EndpointName is the name of the endpoint the chain applies to.
+	EndpointName string
+	// Authenticators lists the distinct http.Authenticator parameter
+	// names the generated functions accept, in first-use order, e.g.
+	// "jwt" for a scheme named "jwt".
+	Authenticators []string
+	// Alternatives lists, one entry per alternative security
+	// requirement, the Authenticators entries to apply in order.
+	Alternatives [][]string
+}
+
+// BuildSecurityChain returns the security chain data for e, or nil if e has
+// no security requirement. It derives one http.Authenticator parameter per
+// distinct scheme referenced across e's alternative SecurityExprs; the
+// concrete Authenticator values (built with NewJWTMiddleware and friends)
+// are supplied by the caller of the generated chain, since schemes like
+// BasicAuth and OAuth2 need a runtime credential-validation callback that
+// can't be derived from the design. Unauthorized and forbidden errors
+// returned by any of these authenticators are expected to be routed
+// through the service's HTTPErrors so they can be customized the same way
+// as any other error response.
+func BuildSecurityChain(e *httpdesign.EndpointExpr) *SecurityChainData {
+	if len(e.Security) == 0 {
+		return nil
+	}
+	data := &SecurityChainData{EndpointName: e.Name()}
+	seen := map[string]bool{}
+	for _, req := range e.Security {
+		alt := make([]string, len(req.Schemes))
+		for j, s := range req.Schemes {
+			name := authenticatorParam(s)
+			alt[j] = name
+			if !seen[name] {
+				seen[name] = true
+				data.Authenticators = append(data.Authenticators, name)
+			}
+		}
+		data.Alternatives = append(data.Alternatives, alt)
+	}
+	return data
+}
+
+// authenticatorParam returns the generated parameter name for the
+// http.Authenticator that validates s: the scheme name with its first rune
+// lowercased, e.g. "jwt" for a scheme named "Jwt" and "aPIKey" for "APIKey".
+func authenticatorParam(s *httpdesign.SecuritySchemeExpr) string {
+	name := s.SchemeName
+	if name == "" {
+		return name
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
+
+// GenerateSecurity renders the security chain code described by
+// BuildSecurityChain for e, so the generated endpoint handler actually
+// applies the declared security requirements before calling the user
+// implementation. The forbidden response returned when every alternative
+// requirement rejects the request uses the same error name as root's
+// "forbidden" entry in HTTPErrors, if any, so it goes through the usual
+// error-encoding path instead of a hardcoded status code.
+func GenerateSecurity(root *httpdesign.RootExpr, e *httpdesign.EndpointExpr) (string, error) {
+	data := BuildSecurityChain(e)
+	if data == nil {
+		return "", nil
+	}
+	return renderSecurityChain(data, httpErrorNames(root)), nil
+}
+
+// renderSecurityChain does the actual source rendering for
+// GenerateSecurity. It is factored out so it can be exercised with a
+// hand-built SecurityChainData and error names without needing a full
+// design tree.
+func renderSecurityChain(data *SecurityChainData, errorNames []string) string {
+	forbidden := "ErrForbidden"
+	for _, name := range errorNames {
+		if strings.EqualFold(name, "forbidden") {
+			forbidden = name
+		}
+	}
+	name := exportName(data.EndpointName)
+
+	var b strings.Builder
+	for i, alt := range data.Alternatives {
+		params := authenticatorParams(alt)
+		fmt.Fprintf(&b, "// %sChain%d applies, in order, the authenticators for the %q endpoint's\n", name, i, data.EndpointName)
+		fmt.Fprintf(&b, "// alternative security requirement: %s.\n", strings.Join(alt, " -> "))
+		fmt.Fprintf(&b, "func %sChain%d(ctx context.Context, r *http.Request, %s) (context.Context, error) {\n", name, i, params)
+		b.WriteString("\tvar err error\n")
+		for _, p := range alt {
+			fmt.Fprintf(&b, "\tif ctx, err = %s(ctx, r); err != nil {\n\t\treturn nil, err\n\t}\n", p)
+		}
+		b.WriteString("\treturn ctx, nil\n}\n\n")
+	}
+
+	topParams := authenticatorParams(data.Authenticators)
+	fmt.Fprintf(&b, "// %sSecurityChain tries each of the %q endpoint's alternative security\n", name, data.EndpointName)
+	b.WriteString("// requirements in turn and returns the context built by the first one that\n")
+	fmt.Fprintf(&b, "// succeeds, or %s — routed through the service's HTTPErrors like any\n", forbidden)
+	b.WriteString("// other error response — if every alternative rejects the request.\n")
+	fmt.Fprintf(&b, "func %sSecurityChain(ctx context.Context, r *http.Request, %s) (context.Context, error) {\n", name, topParams)
+	for i, alt := range data.Alternatives {
+		args := strings.Join(alt, ", ")
+		fmt.Fprintf(&b, "\tif c, err := %sChain%d(ctx, r, %s); err == nil {\n\t\treturn c, nil\n\t}\n", name, i, args)
+	}
+	fmt.Fprintf(&b, "\treturn nil, %s\n}\n", forbidden)
+	return b.String()
+}
+
+// authenticatorParams renders names as a comma-separated list of
+// http.Authenticator-typed parameters, e.g. "jwt http.Authenticator".
+func authenticatorParams(names []string) string {
+	parts := make([]string, len(names))
+	for i, n := range names {
+		parts[i] = n + " http.Authenticator"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// httpErrorNames returns the names of the errors declared on root via the
+// Error DSL, e.g. "unauthorized" or "forbidden", so generated security code
+// can report failures through the same HTTPErrors responses as any other
+// endpoint error instead of a hardcoded status code.
+func httpErrorNames(root *httpdesign.RootExpr) []string {
+	names := make([]string, len(root.HTTPErrors))
+	for i, err := range root.HTTPErrors {
+		names[i] = err.Name
+	}
+	return names
+}
@@ -0,0 +1,127 @@
+package codegen
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	httpdesign "goa.design/goa/http/design"
+)
+
+// SSEData describes the information needed to render the SSE handler for a
+// single endpoint.
+type SSEData struct {
+	// EndpointName is the name of the endpoint served over SSE.
+	EndpointName string
+	// EventAttribute is the result attribute encoded in the "data" field,
+	// "" means the whole result is used.
+	EventAttribute string
+	// IDAttribute is the result attribute encoded in the "id" field, ""
+	// means the field is omitted.
+	IDAttribute string
+	// RetryAttribute is the result attribute encoded in the "retry"
+	// field, "" means the field is omitted.
+	RetryAttribute string
+	// CursorAttribute is the payload attribute that receives the
+	// incoming Last-Event-ID header value, "" means the stream can't be
+	// resumed.
+	CursorAttribute string
+}
+
+// WebSocketData describes the information needed to render the WebSocket
+// handler for a single endpoint.
+type WebSocketData struct {
+	// EndpointName is the name of the endpoint served over WebSocket.
+	EndpointName string
+	// Subprotocols lists the accepted subprotocols, in preference order.
+	Subprotocols []string
+	// PingInterval is how often the handler pings the client, e.g. "30s".
+	PingInterval string
+	// MaxMessageSize is the largest accepted message, in bytes.
+	MaxMessageSize int
+	// Binary frames messages as binary instead of JSON text.
+	Binary bool
+}
+
+// BuildSSEData returns the SSE handler data for e, or nil if e isn't served
+// over SSE.
+func BuildSSEData(e *httpdesign.EndpointExpr) *SSEData {
+	if e.SSE == nil {
+		return nil
+	}
+	return &SSEData{
+		EndpointName:    e.Name(),
+		EventAttribute:  e.SSE.EventAttribute,
+		IDAttribute:     e.SSE.IDAttribute,
+		RetryAttribute:  e.SSE.RetryAttribute,
+		CursorAttribute: e.SSE.CursorAttribute,
+	}
+}
+
+// BuildWebSocketData returns the WebSocket handler data for e, or nil if e
+// isn't served over WebSocket.
+func BuildWebSocketData(e *httpdesign.EndpointExpr) *WebSocketData {
+	if e.WebSocket == nil {
+		return nil
+	}
+	return &WebSocketData{
+		EndpointName:   e.Name(),
+		Subprotocols:   e.WebSocket.Subprotocols,
+		PingInterval:   e.WebSocket.PingInterval,
+		MaxMessageSize: e.WebSocket.MaxMessageSize,
+		Binary:         e.WebSocket.Binary,
+	}
+}
+
+// GenerateStreaming renders the handler for e's streaming transport
+// (SSE or WebSocket), so the generated server actually upgrades the
+// connection and exposes an http.SSEWriter or http.Stream to the endpoint
+// implementation instead of leaving SSE()/WebSocket() as unbacked DSL. It
+// returns "" if e uses a regular request/response exchange.
+func GenerateStreaming(e *httpdesign.EndpointExpr) (string, error) {
+	if sse := BuildSSEData(e); sse != nil {
+		return renderSSEHandler(sse), nil
+	}
+	if ws := BuildWebSocketData(e); ws != nil {
+		return renderWebSocketHandler(ws), nil
+	}
+	return "", nil
+}
+
+// renderSSEHandler does the actual source rendering for the SSE branch of
+// GenerateStreaming. It is factored out so it can be exercised with a
+// hand-built SSEData without needing a full design tree.
+func renderSSEHandler(d *SSEData) string {
+	name := exportName(d.EndpointName)
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Serve%sSSE upgrades r into a Server-Sent Events stream and calls impl\n", name)
+	b.WriteString("// once with the stream writer and the value of the incoming Last-Event-ID\n")
+	b.WriteString("// header, empty when the client is not resuming a stream.\n")
+	fmt.Fprintf(&b, "func Serve%sSSE(w http.ResponseWriter, r *http.Request, impl func(*SSEWriter, string) error) error {\n", name)
+	b.WriteString("\tsw := NewSSEWriter(w)\n")
+	b.WriteString("\tif sw == nil {\n\t\treturn fmt.Errorf(\"streaming unsupported by response writer\")\n\t}\n")
+	b.WriteString("\treturn impl(sw, LastEventID(r))\n}\n")
+	return b.String()
+}
+
+// renderWebSocketHandler does the actual source rendering for the WebSocket
+// branch of GenerateStreaming. It is factored out so it can be exercised
+// with a hand-built WebSocketData without needing a full design tree.
+func renderWebSocketHandler(d *WebSocketData) string {
+	name := exportName(d.EndpointName)
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Serve%sWebSocket upgrades r to a WebSocket connection configured from\n", name)
+	b.WriteString("// the design and calls impl with the resulting Stream.\n")
+	fmt.Fprintf(&b, "func Serve%sWebSocket(w http.ResponseWriter, r *http.Request, impl func(Stream) error) error {\n", name)
+	fmt.Fprintf(&b, "\tcfg := WebSocketConfig{\n")
+	fmt.Fprintf(&b, "\t\tSubprotocols:   %s,\n", goStringSlice(d.Subprotocols))
+	fmt.Fprintf(&b, "\t\tPingInterval:   %q,\n", d.PingInterval)
+	fmt.Fprintf(&b, "\t\tMaxMessageSize: %s,\n", strconv.Itoa(d.MaxMessageSize))
+	fmt.Fprintf(&b, "\t\tBinary:         %t,\n", d.Binary)
+	b.WriteString("\t}\n")
+	b.WriteString("\tstream, err := UpgradeWebSocket(w, r, cfg)\n")
+	b.WriteString("\tif err != nil {\n\t\treturn err\n\t}\n")
+	b.WriteString("\tdefer stream.Close()\n")
+	b.WriteString("\treturn impl(stream)\n}\n")
+	return b.String()
+}
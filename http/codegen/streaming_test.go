@@ -0,0 +1,55 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderSSEHandler(t *testing.T) {
+	d := &SSEData{
+		EndpointName:    "watch",
+		EventAttribute:  "event",
+		IDAttribute:     "id",
+		CursorAttribute: "last_event_id",
+	}
+
+	src := renderSSEHandler(d)
+
+	if !strings.Contains(src, "func ServeWatchSSE(w http.ResponseWriter, r *http.Request, impl func(*SSEWriter, string) error) error {") {
+		t.Fatalf("expected an SSE handler function for watch, got:\n%s", src)
+	}
+	if !strings.Contains(src, "sw := NewSSEWriter(w)") {
+		t.Fatalf("expected the handler to build an SSEWriter, got:\n%s", src)
+	}
+	if !strings.Contains(src, "LastEventID(r)") {
+		t.Fatalf("expected the handler to read the resumption cursor from Last-Event-ID, got:\n%s", src)
+	}
+}
+
+func TestRenderWebSocketHandler(t *testing.T) {
+	d := &WebSocketData{
+		EndpointName:   "chat",
+		Subprotocols:   []string{"chat.v1"},
+		PingInterval:   "30s",
+		MaxMessageSize: 4096,
+		Binary:         true,
+	}
+
+	src := renderWebSocketHandler(d)
+
+	if !strings.Contains(src, "func ServeChatWebSocket(w http.ResponseWriter, r *http.Request, impl func(Stream) error) error {") {
+		t.Fatalf("expected a WebSocket handler function for chat, got:\n%s", src)
+	}
+	if !strings.Contains(src, `Subprotocols:   []string{"chat.v1"},`) {
+		t.Fatalf("expected the configured subprotocols to be rendered, got:\n%s", src)
+	}
+	if !strings.Contains(src, `PingInterval:   "30s",`) {
+		t.Fatalf("expected the configured ping interval to be rendered, got:\n%s", src)
+	}
+	if !strings.Contains(src, "MaxMessageSize: 4096,") {
+		t.Fatalf("expected the configured max message size to be rendered, got:\n%s", src)
+	}
+	if !strings.Contains(src, "UpgradeWebSocket(w, r, cfg)") {
+		t.Fatalf("expected the handler to call UpgradeWebSocket, got:\n%s", src)
+	}
+}
@@ -0,0 +1,230 @@
+package codegen
+
+import (
+	"fmt"
+	"strings"
+
+	"goa.design/goa/design"
+	httpdesign "goa.design/goa/http/design"
+)
+
+// Generate renders the HTTP transcoding handlers described by
+// BuildTranscodingData, one decode function per HTTPRuleExpr (including
+// additional bindings), so the generated server can expose svc's methods
+// over HTTP+JSON in addition to gRPC. It returns "" if svc has no HTTP
+// rules to transcode.
+func Generate(svc *httpdesign.ServiceExpr) (string, error) {
+	data := BuildTranscodingData(svc)
+	if len(data) == 0 {
+		return "", nil
+	}
+	return renderTranscoding(svc.Name(), data), nil
+}
+
+// renderTranscoding does the actual source rendering for Generate. It is
+// factored out so it can be exercised with hand-built TranscodingData
+// without needing a full design tree.
+func renderTranscoding(pkg string, data []*TranscodingData) string {
+	var b strings.Builder
+	b.WriteString("// Code generated by goa, DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", strings.ToLower(pkg))
+	b.WriteString("import (\n")
+	b.WriteString("\t\"encoding/json\"\n")
+	b.WriteString("\t\"net/http\"\n")
+	if needsStrconv(data) {
+		b.WriteString("\t\"strconv\"\n")
+	}
+	b.WriteString(")\n\n")
+	for i, t := range data {
+		writeTranscodingHandler(&b, t, i)
+	}
+	return b.String()
+}
+
+// needsStrconv reports whether any parameter across data is bound to a
+// non-string payload attribute, in which case the generated handlers need
+// the strconv package to convert the path value.
+func needsStrconv(data []*TranscodingData) bool {
+	for _, t := range data {
+		for _, p := range t.Params {
+			if _, ok := numericConverter(p.Type); ok || p.Type == design.Boolean {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// writeTranscodingHandler renders the decode function for a single
+// TranscodingData entry: it reads the path wildcards from the request via
+// the stdlib router's PathValue and, if the rule maps part of the payload
+// (or, with "*", the whole payload) to the body, decodes it as JSON.
+func writeTranscodingHandler(b *strings.Builder, t *TranscodingData, idx int) {
+	name := transcodingHandlerName(t, idx)
+	fmt.Fprintf(b, "// %s decodes the %s %s HTTP request into a %sRequest.\n", name, t.Verb, t.Path, t.MethodName)
+	fmt.Fprintf(b, "func %s(r *http.Request) (*%sRequest, error) {\n", name, t.MethodName)
+	fmt.Fprintf(b, "\treq := &%sRequest{}\n", t.MethodName)
+	for _, p := range t.Params {
+		writeTranscodingParam(b, p)
+	}
+	if t.Body == "*" {
+		b.WriteString("\tif err := json.NewDecoder(r.Body).Decode(req); err != nil {\n\t\treturn nil, err\n\t}\n")
+	} else if t.Body != "" {
+		fmt.Fprintf(b, "\tif err := json.NewDecoder(r.Body).Decode(&req.%s); err != nil {\n\t\treturn nil, err\n\t}\n", exportName(t.Body))
+	}
+	b.WriteString("\treturn req, nil\n}\n\n")
+}
+
+// writeTranscodingParam renders the statement(s) that read path parameter p
+// from the request and assign it to the matching request field, converting
+// it from string to p.Type when the field isn't itself a string.
+func writeTranscodingParam(b *strings.Builder, p TranscodingParam) {
+	field := exportName(p.Name)
+	if conv, ok := numericConverter(p.Type); ok {
+		fmt.Fprintf(b, "\t%sVal, err := %s(r.PathValue(%q)%s)\n", field, conv.parseFunc, p.Name, conv.parseArgs)
+		b.WriteString("\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+		fmt.Fprintf(b, "\treq.%s = %s(%sVal)\n", field, conv.goType, field)
+		return
+	}
+	if p.Type == design.Boolean {
+		fmt.Fprintf(b, "\t%sVal, err := strconv.ParseBool(r.PathValue(%q))\n", field, p.Name)
+		b.WriteString("\tif err != nil {\n\t\treturn nil, err\n\t}\n")
+		fmt.Fprintf(b, "\treq.%s = %sVal\n", field, field)
+		return
+	}
+	fmt.Fprintf(b, "\treq.%s = r.PathValue(%q)\n", field, p.Name)
+}
+
+// numericConverterInfo holds the Go type a path parameter is converted to
+// and the strconv call (plus any trailing arguments) used to parse it.
+type numericConverterInfo struct {
+	goType    string
+	parseFunc string
+	parseArgs string
+}
+
+// numericConverter returns the strconv-based conversion used to parse a path
+// parameter bound to t from string, and false if t isn't a numeric primitive
+// (i.e. it should be assigned as a plain string).
+func numericConverter(t design.DataType) (numericConverterInfo, bool) {
+	switch t {
+	case httpdesign.Int:
+		return numericConverterInfo{"int", "strconv.Atoi", ""}, true
+	case httpdesign.Int32:
+		return numericConverterInfo{"int32", "strconv.ParseInt", ", 10, 32"}, true
+	case httpdesign.Int64:
+		return numericConverterInfo{"int64", "strconv.ParseInt", ", 10, 64"}, true
+	case httpdesign.UInt:
+		return numericConverterInfo{"uint", "strconv.ParseUint", ", 10, 64"}, true
+	case httpdesign.UInt32:
+		return numericConverterInfo{"uint32", "strconv.ParseUint", ", 10, 32"}, true
+	case httpdesign.UInt64:
+		return numericConverterInfo{"uint64", "strconv.ParseUint", ", 10, 64"}, true
+	case httpdesign.Float32:
+		return numericConverterInfo{"float32", "strconv.ParseFloat", ", 32"}, true
+	case httpdesign.Float64:
+		return numericConverterInfo{"float64", "strconv.ParseFloat", ", 64"}, true
+	default:
+		return numericConverterInfo{}, false
+	}
+}
+
+// exportName capitalizes the first rune of name so it can be used as an
+// exported Go identifier, e.g. a request struct field name.
+func exportName(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToUpper(name[:1]) + name[1:]
+}
+
+// TranscodingData captures the information needed by renderTranscoding to
+// generate the gRPC-to-HTTP binding for a single HTTPRuleExpr.
+type TranscodingData struct {
+	// ServiceName is the name of the parent service.
+	ServiceName string
+	// MethodName is the name of the transport-agnostic method.
+	MethodName string
+	// Verb is the HTTP method, e.g. "GET".
+	Verb string
+	// Path is the request path rewritten for the HTTP router, see
+	// HTTPRuleExpr.RequestPath.
+	Path string
+	// Params lists the path parameters in the order they appear in Path,
+	// together with the payload attribute type each one is bound to.
+	Params []TranscodingParam
+	// Body is the payload attribute mapped to the request body, "*" if
+	// the whole payload is, "" if the rule has no body.
+	Body string
+	// ResponseBody is the result attribute mapped to the response body.
+	ResponseBody string
+}
+
+// TranscodingParam describes a single path wildcard captured by an
+// HTTPRuleExpr pattern and the payload attribute it is bound to.
+type TranscodingParam struct {
+	// Name is the path variable name as it appears in the rule pattern.
+	Name string
+	// Type is the type of the payload attribute Name is bound to. It
+	// defaults to design.String when the attribute can't be resolved,
+	// e.g. because the rule has no payload to validate against.
+	Type design.DataType
+}
+
+// BuildTranscodingData walks svc.HTTPEndpoints and returns the transcoding
+// data for every HTTPRuleExpr attached to them (including additional
+// bindings), so that the generated server exposes the same method over both
+// gRPC and HTTP+JSON.
+func BuildTranscodingData(svc *httpdesign.ServiceExpr) []*TranscodingData {
+	var data []*TranscodingData
+	for _, e := range svc.HTTPEndpoints {
+		for _, r := range e.Rules {
+			data = append(data, transcodingDataFor(svc, e, r))
+			for _, ab := range r.AdditionalBindings {
+				data = append(data, transcodingDataFor(svc, e, ab))
+			}
+		}
+	}
+	return data
+}
+
+func transcodingDataFor(svc *httpdesign.ServiceExpr, e *httpdesign.EndpointExpr, r *httpdesign.HTTPRuleExpr) *TranscodingData {
+	return &TranscodingData{
+		ServiceName:  svc.Name(),
+		MethodName:   e.Name(),
+		Verb:         strings.ToUpper(r.Method),
+		Path:         r.RequestPath(),
+		Params:       transcodingParams(e, r),
+		Body:         r.Body,
+		ResponseBody: r.ResponseBody,
+	}
+}
+
+// transcodingParams resolves each of r's path wildcards against e's method
+// payload to find the attribute type used to convert the path value from
+// string, defaulting to design.String when the payload or the attribute
+// can't be found.
+func transcodingParams(e *httpdesign.EndpointExpr, r *httpdesign.HTTPRuleExpr) []TranscodingParam {
+	var obj *design.Object
+	if e.MethodExpr != nil && e.MethodExpr.Payload != nil {
+		obj = design.AsObject(e.MethodExpr.Payload.Type)
+	}
+	names := r.Wildcards()
+	params := make([]TranscodingParam, len(names))
+	for i, name := range names {
+		var typ design.DataType = design.String
+		if obj != nil {
+			if attr := obj.Attribute(name); attr != nil {
+				typ = attr.Type
+			}
+		}
+		params[i] = TranscodingParam{Name: name, Type: typ}
+	}
+	return params
+}
+
+// transcodingHandlerName returns the name of the generated HTTP handler
+// function for the given rule, e.g. "DecodeGetMessageRequestHTTPRule0".
+func transcodingHandlerName(t *TranscodingData, idx int) string {
+	return fmt.Sprintf("Decode%sRequestHTTPRule%d", t.MethodName, idx)
+}
@@ -0,0 +1,62 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"goa.design/goa/design"
+)
+
+func TestRenderTranscoding(t *testing.T) {
+	data := []*TranscodingData{
+		{
+			ServiceName:  "widgets",
+			MethodName:   "ShowWidget",
+			Verb:         "GET",
+			Path:         "/widgets/{id}",
+			Params:       []TranscodingParam{{Name: "id", Type: design.String}},
+			ResponseBody: "widget",
+		},
+		{
+			ServiceName: "widgets",
+			MethodName:  "CreateWidget",
+			Verb:        "POST",
+			Path:        "/widgets",
+			Body:        "widget",
+		},
+		{
+			ServiceName: "messages",
+			MethodName:  "ShowMessage",
+			Verb:        "GET",
+			Path:        "/v1/messages/{message_id}",
+			Params:      []TranscodingParam{{Name: "message_id", Type: design.Int64}},
+		},
+	}
+
+	src := renderTranscoding("widgets", data)
+
+	if !strings.Contains(src, "package widgets") {
+		t.Fatalf("expected generated source to declare package widgets, got:\n%s", src)
+	}
+	if !strings.Contains(src, `"strconv"`) {
+		t.Fatalf("expected the strconv import to be pulled in for the numeric message_id param, got:\n%s", src)
+	}
+	if !strings.Contains(src, "func DecodeShowWidgetRequestHTTPRule0(r *http.Request) (*ShowWidgetRequest, error) {") {
+		t.Fatalf("expected a decode function for ShowWidget, got:\n%s", src)
+	}
+	if !strings.Contains(src, `req.Id = r.PathValue("id")`) {
+		t.Fatalf("expected the id wildcard to be read via PathValue, got:\n%s", src)
+	}
+	if !strings.Contains(src, "func DecodeCreateWidgetRequestHTTPRule1(r *http.Request) (*CreateWidgetRequest, error) {") {
+		t.Fatalf("expected a decode function for CreateWidget, got:\n%s", src)
+	}
+	if !strings.Contains(src, "json.NewDecoder(r.Body).Decode(&req.Widget)") {
+		t.Fatalf("expected the body to be JSON-decoded into req.Widget, got:\n%s", src)
+	}
+	if !strings.Contains(src, `Message_idVal, err := strconv.ParseInt(r.PathValue("message_id"), 10, 64)`) {
+		t.Fatalf("expected the numeric message_id param to be parsed with strconv.ParseInt, got:\n%s", src)
+	}
+	if !strings.Contains(src, "req.Message_id = int64(Message_idVal)") {
+		t.Fatalf("expected the parsed message_id value to be converted to int64, got:\n%s", src)
+	}
+}
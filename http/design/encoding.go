@@ -0,0 +1,103 @@
+package design
+
+import "fmt"
+
+type (
+	// EncoderExpr describes a media type together with the Go package
+	// that implements the encoder used to generate responses of that
+	// type.
+	EncoderExpr struct {
+		// MIMETypes is the list of media types the encoder applies to,
+		// e.g. "application/json" or "application/*".
+		MIMETypes []string
+		// PackagePath is the import path of the package that
+		// implements the encoder, e.g.
+		// "github.com/vmihailenco/msgpack". An empty PackagePath means
+		// the media type is handled by the built-in encoder.
+		PackagePath string
+		// Function is the name of the function used to instantiate
+		// the encoder, defaults to "NewEncoder".
+		Function string
+		// Streaming indicates the encoder supports writing multiple
+		// values to the same io.Writer without buffering, e.g. the
+		// built-in "application/x-ndjson" encoder.
+		Streaming bool
+	}
+
+	// DecoderExpr describes a media type together with the Go package
+	// that implements the decoder used to read requests of that type.
+	DecoderExpr struct {
+		// MIMETypes is the list of media types the decoder applies
+		// to.
+		MIMETypes []string
+		// PackagePath is the import path of the package that
+		// implements the decoder. An empty PackagePath means the
+		// media type is handled by the built-in decoder.
+		PackagePath string
+		// Function is the name of the function used to instantiate
+		// the decoder, defaults to "NewDecoder".
+		Function string
+	}
+)
+
+// builtinEncoders lists the media types the generated code knows how to
+// encode without requiring a user supplied package.
+var builtinEncoders = []string{
+	"application/json",
+	"application/xml",
+	"application/msgpack",
+	"application/x-protobuf",
+	"application/x-ndjson",
+}
+
+// IsBuiltinMediaType returns true if mime is handled by the built-in
+// encoders and decoders shipped with goa.
+func IsBuiltinMediaType(mime string) bool {
+	for _, m := range builtinEncoders {
+		if m == mime {
+			return true
+		}
+	}
+	return false
+}
+
+// EncoderFor returns the encoder registered for the given media type, if
+// any.
+func (r *RootExpr) EncoderFor(mime string) *EncoderExpr {
+	for _, e := range r.Encoders {
+		for _, m := range e.MIMETypes {
+			if m == mime {
+				return e
+			}
+		}
+	}
+	return nil
+}
+
+// DecoderFor returns the decoder registered for the given media type, if
+// any.
+func (r *RootExpr) DecoderFor(mime string) *DecoderExpr {
+	for _, d := range r.Decoders {
+		for _, m := range d.MIMETypes {
+			if m == mime {
+				return d
+			}
+		}
+	}
+	return nil
+}
+
+// validateEncoding makes sure a user registered encoder or decoder either
+// targets a built-in media type or provides a package path to use to encode
+// or decode it.
+func validateEncoding(mimeTypes []string, packagePath string) error {
+	if packagePath != "" {
+		return nil
+	}
+	for _, m := range mimeTypes {
+		if !IsBuiltinMediaType(m) {
+			return fmt.Errorf("no built-in encoder or decoder for media type %q, a package path must be provided", m)
+		}
+	}
+	return nil
+}
@@ -21,9 +21,54 @@ type (
 		FilePath string
 		// RequestPath is the HTTP path that servers the assets.
 		RequestPath string
+		// ETag controls whether and how the generated handler computes
+		// an ETag response header for served files, see the ETag*
+		// constants. It defaults to ETagOff.
+		ETag ETagMode
+		// Ranges enables support for the Range request header, serving
+		// "206 Partial Content" responses (including multipart
+		// byte-range responses) when set.
+		Ranges bool
+		// PrecompressedEncodings lists the content codings, e.g.
+		// "gzip" or "br", that the handler looks for as
+		// pre-compressed siblings of a requested file (e.g.
+		// "foo.js.gz") when the client's Accept-Encoding allows it.
+		PrecompressedEncodings []string
+		// SPAFallback is the name of the index file served, with a 200
+		// status, for any request under RequestPath that does not map
+		// to an existing file. It is used to support single page
+		// application client-side routers.
+		SPAFallback string
+		// CacheControl is the value of the Cache-Control header set on
+		// every response, empty means no Cache-Control header is set.
+		CacheControl string
+		// IndexNames lists the file names tried, in order, when a
+		// request maps to a directory, defaults to []string{"index.html"}.
+		IndexNames []string
+		// Embed indicates that the generated code should use a
+		// //go:embed directive to bundle FilePath into the resulting
+		// binary and serve it from an fs.FS instead of the OS file
+		// system.
+		Embed bool
 		// Metadata is a list of key/value pairs
 		Metadata design.MetadataExpr
 	}
+
+	// ETagMode is the type of the values of the FileServerExpr ETag
+	// field.
+	ETagMode string
+)
+
+const (
+	// ETagOff disables ETag generation.
+	ETagOff ETagMode = "off"
+	// ETagStrong generates a strong ETag from a hash of the file
+	// content, changing any byte of the file changes the ETag.
+	ETagStrong ETagMode = "strong"
+	// ETagWeak generates a weak ETag ("W/...") derived from the file
+	// size and modification time, cheaper to compute than ETagStrong but
+	// only semantically equivalent across revisions.
+	ETagWeak ETagMode = "weak"
 )
 
 // EvalName returns the generic definition name used in error messages.
@@ -36,13 +81,35 @@ func (f *FileServerExpr) EvalName() string {
 	return prefix + suffix
 }
 
-// Finalize normalizes the request path.
+// Finalize normalizes the request path and fills in the defaults for the
+// fields left unset by the DSL.
 func (f *FileServerExpr) Finalize() {
 	f.RequestPath = path.Join(Root.Path, f.Service.Path, f.RequestPath)
 	// Make sure request path starts with a "/" so codegen can rely on it.
 	if !strings.HasPrefix(f.RequestPath, "/") {
 		f.RequestPath = "/" + f.RequestPath
 	}
+	if f.ETag == "" {
+		f.ETag = ETagOff
+	}
+	if len(f.IndexNames) == 0 {
+		f.IndexNames = []string{"index.html"}
+	}
+}
+
+// Validate makes sure the file server expression is consistent, e.g. that
+// SPAFallback isn't combined with a single-file server and that the ETag
+// mode is one of the known values.
+func (f *FileServerExpr) Validate() error {
+	switch f.ETag {
+	case "", ETagOff, ETagStrong, ETagWeak:
+	default:
+		return fmt.Errorf("%s: invalid ETag mode %q, must be one of %q, %q or %q", f.EvalName(), f.ETag, ETagOff, ETagStrong, ETagWeak)
+	}
+	if f.SPAFallback != "" && !f.IsDir() {
+		return fmt.Errorf("%s: SPAFallback can only be used with a file server that serves a directory", f.EvalName())
+	}
+	return nil
 }
 
 // IsDir returns true if the file server serves a directory, false otherwise.
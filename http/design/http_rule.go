@@ -0,0 +1,105 @@
+package design
+
+import (
+	"fmt"
+	"strings"
+
+	"goa.design/goa/design"
+)
+
+type (
+	// HTTPRuleExpr describes the transcoding between a transport-agnostic
+	// method and an HTTP request/response pair using the same semantics as
+	// Google's google.api.http annotation. It lets a single service method
+	// drive both a gRPC transport and a REST/JSON transport without
+	// duplicating the routing information in the design.
+	HTTPRuleExpr struct {
+		// Endpoint is the parent endpoint.
+		Endpoint *EndpointExpr
+		// Method is the HTTP method, e.g. "GET", "POST".
+		Method string
+		// Pattern is the URL template, e.g.
+		// "/v1/messages/{message_id}" or "/v1/{name=shelves/*/books/*}".
+		Pattern string
+		// Body is the name of the request attribute that is mapped to
+		// the HTTP request body. A value of "*" means the whole
+		// payload is used as the body, an empty value means the
+		// method has no body.
+		Body string
+		// ResponseBody is the name of the result attribute that is
+		// mapped to the HTTP response body. A value of "*" means the
+		// whole result is used as the body.
+		ResponseBody string
+		// AdditionalBindings lists alternate rules that also route to
+		// the same endpoint, mirroring the "additional_bindings"
+		// field of google.api.http.
+		AdditionalBindings []*HTTPRuleExpr
+		// Metadata is a set of key/value pairs with semantic that is
+		// specific to each generator.
+		Metadata design.MetadataExpr
+	}
+)
+
+// EvalName returns the generic definition name used in error messages.
+func (r *HTTPRuleExpr) EvalName() string {
+	suffix := fmt.Sprintf("HTTP rule %s %s", r.Method, r.Pattern)
+	if r.Endpoint != nil {
+		return r.Endpoint.EvalName() + " " + suffix
+	}
+	return suffix
+}
+
+// Validate makes sure the rule pattern is well-formed and that the body
+// selector, if any, refers to an attribute of the method payload.
+func (r *HTTPRuleExpr) Validate() error {
+	if r.Pattern == "" {
+		return fmt.Errorf("%s: pattern cannot be empty", r.EvalName())
+	}
+	if !strings.HasPrefix(r.Pattern, "/") {
+		return fmt.Errorf("%s: pattern must start with a \"/\"", r.EvalName())
+	}
+	if r.Body != "" && r.Body != "*" && r.Endpoint != nil && r.Endpoint.MethodExpr != nil {
+		if r.Endpoint.MethodExpr.Payload != nil {
+			obj := design.AsObject(r.Endpoint.MethodExpr.Payload.Type)
+			if obj != nil && obj.Attribute(r.Body) == nil {
+				return fmt.Errorf("%s: body %q is not an attribute of the method payload", r.EvalName(), r.Body)
+			}
+		}
+	}
+	for _, a := range r.AdditionalBindings {
+		if err := a.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Wildcards returns the names of the path variables captured by the rule
+// pattern, expanding "{name=a/*/b/*}" style captures the same way
+// ExtractRuleWildcards does.
+func (r *HTTPRuleExpr) Wildcards() []string {
+	return ExtractRuleWildcards(r.Pattern)
+}
+
+// RequestPath returns the pattern rewritten so that it can be routed using
+// the router already used by the rest of the HTTP transport: "{name}"
+// captures are left as single-segment wildcards, while "{name=a/*/b/*}"
+// captures, which embed a "/" in the segment they match, are turned into a
+// trailing "{name...}" wildcard so the full multi-segment value is still
+// routed to a single path parameter.
+func (r *HTTPRuleExpr) RequestPath() string {
+	return RuleWildcardRegex.ReplaceAllStringFunc(r.Pattern, func(m string) string {
+		sub := RuleWildcardRegex.FindStringSubmatch(m)
+		name, capture := sub[1], sub[2]
+		if strings.Contains(capture, "/") {
+			return "{" + name + "...}"
+		}
+		return "{" + name + "}"
+	})
+}
+
+// HasBody returns true if the rule maps part of (or the whole) request to an
+// HTTP body.
+func (r *HTTPRuleExpr) HasBody() bool {
+	return r.Body != ""
+}
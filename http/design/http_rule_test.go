@@ -0,0 +1,64 @@
+package design
+
+import "testing"
+
+func TestHTTPRuleExprWildcards(t *testing.T) {
+	cases := []struct {
+		pattern string
+		want    []string
+	}{
+		{"/v1/messages/{message_id}", []string{"message_id"}},
+		{"/v1/{name=shelves/*/books/*}", []string{"name"}},
+		{"/v1/{shelf}/{name=books/*}", []string{"shelf", "name"}},
+	}
+	for _, c := range cases {
+		r := &HTTPRuleExpr{Pattern: c.pattern}
+		got := r.Wildcards()
+		if len(got) != len(c.want) {
+			t.Fatalf("pattern %q: got wildcards %v, want %v", c.pattern, got, c.want)
+		}
+		for i := range got {
+			if got[i] != c.want[i] {
+				t.Fatalf("pattern %q: got wildcards %v, want %v", c.pattern, got, c.want)
+			}
+		}
+	}
+}
+
+func TestHTTPRuleExprRequestPath(t *testing.T) {
+	cases := []struct {
+		pattern string
+		want    string
+	}{
+		{"/v1/messages/{message_id}", "/v1/messages/{message_id}"},
+		{"/v1/{name=shelves/*/books/*}", "/v1/{name...}"},
+		{"/v1/{shelf}/{name=books/*}", "/v1/{shelf}/{name...}"},
+	}
+	for _, c := range cases {
+		r := &HTTPRuleExpr{Pattern: c.pattern}
+		if got := r.RequestPath(); got != c.want {
+			t.Fatalf("pattern %q: got RequestPath() = %q, want %q", c.pattern, got, c.want)
+		}
+	}
+}
+
+func TestHTTPRuleExprValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		rule    *HTTPRuleExpr
+		wantErr bool
+	}{
+		{"empty pattern", &HTTPRuleExpr{Method: "GET"}, true},
+		{"missing leading slash", &HTTPRuleExpr{Method: "GET", Pattern: "v1/messages"}, true},
+		{"valid", &HTTPRuleExpr{Method: "GET", Pattern: "/v1/messages"}, false},
+	}
+	for _, c := range cases {
+		err := c.rule.Validate()
+		if c.wantErr && err == nil {
+			t.Errorf("%s: expected an error, got nil", c.name)
+		}
+		if !c.wantErr && err != nil {
+			t.Errorf("%s: expected no error, got %v", c.name, err)
+		}
+	}
+}
@@ -18,6 +18,13 @@ var (
 	// parameters.
 	WildcardRegex = regexp.MustCompile(`/{\*?([a-zA-Z0-9_]+)}`)
 
+	// RuleWildcardRegex is the regular expression used to capture the
+	// path parameters of a google.api.http style template. In addition to
+	// the plain "{name}" form supported by WildcardRegex it also matches
+	// the "{name=segment/*/segment/*}" form used to bind a variable to a
+	// multi-segment path capture (e.g. "{name=shelves/*/books/*}").
+	RuleWildcardRegex = regexp.MustCompile(`{([a-zA-Z0-9_]+)(?:=([^}]+))?}`)
+
 	// ErrorResult is the built-in result type for error responses.
 	ErrorResult = design.ErrorResult
 )
@@ -97,15 +104,26 @@ type (
 		// HTTP endpoints.
 		Path string
 		// Consumes lists the mime types supported by the API
-		// controllers.
+		// controllers. It is computed from Decoders during Finalize.
 		Consumes []string
 		// Produces lists the mime types generated by the API
-		// controllers.
+		// controllers. It is computed from Encoders during Finalize.
 		Produces []string
+		// Decoders lists the request body decoders registered via the
+		// Consumes DSL, in the order content negotiation should
+		// consider them.
+		Decoders []*DecoderExpr
+		// Encoders lists the response body encoders registered via
+		// the Produces DSL, in the order content negotiation should
+		// consider them.
+		Encoders []*EncoderExpr
 		// HTTPServices contains the services created by the DSL.
 		HTTPServices []*ServiceExpr
 		// HTTPErrors lists the error HTTP responses.
 		HTTPErrors []*ErrorExpr
+		// SecuritySchemes lists the authentication schemes that
+		// endpoints may require via the Security DSL.
+		SecuritySchemes []*SecuritySchemeExpr
 		// Metadata is a set of key/value pairs with semantic that is
 		// specific to each generator.
 		Metadata design.MetadataExpr
@@ -129,7 +147,7 @@ func (r *RootExpr) Schemes() []string {
 	}
 	schemes := make(map[string]bool)
 	for _, s := range r.Design.API.Servers {
-		if u, err := url.Parse(s.URL); err != nil {
+		if u, err := url.Parse(s.URL); err == nil {
 			schemes[u.Scheme] = true
 		}
 	}
@@ -207,8 +225,13 @@ func (r *RootExpr) WalkSets(walk eval.SetWalker) {
 		services  eval.ExpressionSet
 		endpoints eval.ExpressionSet
 		servers   eval.ExpressionSet
+		schemes   eval.ExpressionSet
 	)
 	{
+		schemes = make(eval.ExpressionSet, len(r.SecuritySchemes))
+		for i, s := range r.SecuritySchemes {
+			schemes[i] = s
+		}
 		services = make(eval.ExpressionSet, len(r.HTTPServices))
 		sort.SliceStable(r.HTTPServices, func(i, j int) bool {
 			if r.HTTPServices[j].ParentName == r.HTTPServices[i].Name() {
@@ -226,11 +249,32 @@ func (r *RootExpr) WalkSets(walk eval.SetWalker) {
 			}
 		}
 	}
+	walk(schemes)
 	walk(services)
 	walk(endpoints)
 	walk(servers)
 }
 
+// Finalize makes sure the Consumes and Produces lists reflect the
+// registered decoders and encoders, falling back to the built-in JSON codec
+// when the DSL did not register any.
+func (r *RootExpr) Finalize() {
+	if len(r.Decoders) == 0 {
+		r.Decoders = []*DecoderExpr{{MIMETypes: []string{"application/json"}}}
+	}
+	if len(r.Encoders) == 0 {
+		r.Encoders = []*EncoderExpr{{MIMETypes: []string{"application/json"}}}
+	}
+	r.Consumes = nil
+	for _, d := range r.Decoders {
+		r.Consumes = append(r.Consumes, d.MIMETypes...)
+	}
+	r.Produces = nil
+	for _, e := range r.Encoders {
+		r.Produces = append(r.Produces, e.MIMETypes...)
+	}
+}
+
 // DependsOn is a no-op as the DSL runs when loaded.
 func (r *RootExpr) DependsOn() []eval.Root { return nil }
 
@@ -252,6 +296,19 @@ func ExtractWildcards(path string) []string {
 	return wcs
 }
 
+// ExtractRuleWildcards returns the names of the wildcards that appear in a
+// google.api.http style path pattern, e.g. "shelves/{shelf}/books/{name=*}".
+// Unlike ExtractWildcards it also recognizes captures that bind to more than
+// one path segment using the "{name=a/*/b/*}" syntax.
+func ExtractRuleWildcards(pattern string) []string {
+	matches := RuleWildcardRegex.FindAllStringSubmatch(pattern, -1)
+	wcs := make([]string, len(matches))
+	for i, m := range matches {
+		wcs[i] = m[1]
+	}
+	return wcs
+}
+
 // NameMap returns the attribute and HTTP element name encoded in the given
 // string. The encoding uses a simple "attribute:element" notation which allows
 // to map header or body field names to underlying attributes. The second
@@ -0,0 +1,152 @@
+package design
+
+import (
+	"fmt"
+
+	"goa.design/goa/design"
+)
+
+type (
+	// SecuritySchemeExpr defines an authentication scheme that can be
+	// required by one or more endpoints via the Security DSL. It mirrors
+	// the way ErrorExpr is attached to RootExpr: schemes are declared
+	// once and referenced by name from endpoints.
+	SecuritySchemeExpr struct {
+		// SchemeName is the name used to reference the scheme from the
+		// Security DSL.
+		SchemeName string
+		// Kind identifies which of the supported scheme shapes this
+		// expression describes.
+		Kind SecuritySchemeKind
+		// Description is the scheme description used in the generated
+		// documentation.
+		Description string
+		// In is the location of the credential for APIKey schemes:
+		// "header", "query" or "cookie".
+		In string
+		// Name is the name of the header, query string parameter or
+		// cookie that carries the credential for APIKey schemes.
+		Name string
+		// JWKSURL is the URL the JWT middleware fetches the signing
+		// keys from.
+		JWKSURL string
+		// JWKSRefreshInterval is how often the middleware refreshes
+		// the key set fetched from JWKSURL.
+		JWKSRefreshInterval string
+		// Flows lists the OAuth2 flows supported by the scheme.
+		Flows []*OAuth2FlowExpr
+		// Scopes lists the scopes recognized by a JWT or OAuth2
+		// scheme, as "name: description" pairs.
+		Scopes map[string]string
+		// Metadata is a set of key/value pairs with semantic that is
+		// specific to each generator.
+		Metadata design.MetadataExpr
+	}
+
+	// SecuritySchemeKind is the type of the SecuritySchemeExpr Kind
+	// field.
+	SecuritySchemeKind int
+
+	// OAuth2FlowExpr describes a single OAuth2 flow, e.g. the
+	// authorization-code or client-credentials flow.
+	OAuth2FlowExpr struct {
+		// Kind is one of "authorization_code", "client_credentials",
+		// "implicit" or "password".
+		Kind string
+		// AuthorizationURL is the URL used by the authorization-code
+		// and implicit flows.
+		AuthorizationURL string
+		// TokenURL is the URL used by every flow except implicit.
+		TokenURL string
+		// RefreshURL is the URL the client calls to refresh a token,
+		// optional.
+		RefreshURL string
+	}
+
+	// SecurityExpr represents a set of security requirements applied to
+	// an endpoint: the endpoint is accessible if the caller satisfies
+	// any one of the requirements, each requirement possibly needing
+	// more than one scheme.
+	SecurityExpr struct {
+		// Endpoint is the endpoint the requirement applies to.
+		Endpoint *EndpointExpr
+		// Schemes lists the schemes that together satisfy this
+		// requirement.
+		Schemes []*SecuritySchemeExpr
+		// Scopes lists the scopes required for the schemes that
+		// support them (JWT, OAuth2).
+		Scopes []string
+	}
+)
+
+const (
+	// APIKeyKind identifies a SecuritySchemeExpr that authenticates
+	// requests using a static key carried in a header, query string
+	// parameter or cookie.
+	APIKeyKind SecuritySchemeKind = iota + 1
+	// BasicAuthKind identifies a SecuritySchemeExpr that authenticates
+	// requests using HTTP Basic authentication.
+	BasicAuthKind
+	// JWTKind identifies a SecuritySchemeExpr that authenticates
+	// requests using a JSON Web Token.
+	JWTKind
+	// OAuth2Kind identifies a SecuritySchemeExpr that authenticates
+	// requests using one or more OAuth2 flows.
+	OAuth2Kind
+)
+
+// EvalName returns the generic definition name used in error messages.
+func (s *SecuritySchemeExpr) EvalName() string {
+	return fmt.Sprintf("security scheme %q", s.SchemeName)
+}
+
+// Validate makes sure the scheme is consistent with its kind, e.g. that an
+// APIKey scheme specifies where the key is carried.
+func (s *SecuritySchemeExpr) Validate() error {
+	switch s.Kind {
+	case APIKeyKind:
+		if s.In == "" || s.Name == "" {
+			return fmt.Errorf("%s: APIKey scheme must set both In and Name", s.EvalName())
+		}
+	case JWTKind:
+		if s.JWKSURL == "" {
+			return fmt.Errorf("%s: JWT scheme must set JWKSURL", s.EvalName())
+		}
+	case OAuth2Kind:
+		if len(s.Flows) == 0 {
+			return fmt.Errorf("%s: OAuth2 scheme must define at least one flow", s.EvalName())
+		}
+	case BasicAuthKind:
+	default:
+		return fmt.Errorf("%s: unknown security scheme kind", s.EvalName())
+	}
+	return nil
+}
+
+// EvalName returns the generic definition name used in error messages.
+func (s *SecurityExpr) EvalName() string {
+	if s.Endpoint != nil {
+		return s.Endpoint.EvalName() + " security"
+	}
+	return "security"
+}
+
+// SchemeNames returns the names of the schemes referenced by the security
+// requirement.
+func (s *SecurityExpr) SchemeNames() []string {
+	names := make([]string, len(s.Schemes))
+	for i, sch := range s.Schemes {
+		names[i] = sch.SchemeName
+	}
+	return names
+}
+
+// Scheme returns the scheme registered under name, if any.
+func (r *RootExpr) Scheme(name string) *SecuritySchemeExpr {
+	for _, s := range r.SecuritySchemes {
+		if s.SchemeName == name {
+			return s
+		}
+	}
+	return nil
+}
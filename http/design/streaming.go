@@ -0,0 +1,110 @@
+package design
+
+import (
+	"fmt"
+
+	"goa.design/goa/design"
+)
+
+type (
+	// StreamKind identifies which kind of streaming transport an
+	// endpoint uses in addition to, or instead of, a regular HTTP
+	// request/response exchange.
+	StreamKind int
+
+	// SSEExpr configures an endpoint exposed as a Server-Sent Events
+	// stream.
+	SSEExpr struct {
+		// Endpoint is the parent endpoint.
+		Endpoint *EndpointExpr
+		// EventAttribute is the name of the result attribute holding
+		// the event payload encoded in the SSE "data" field. An empty
+		// value means the whole result is used.
+		EventAttribute string
+		// IDAttribute is the name of the result attribute mapped to
+		// the SSE "id" field, used by clients to resume a stream via
+		// Last-Event-ID.
+		IDAttribute string
+		// RetryAttribute is the name of the result attribute mapped to
+		// the SSE "retry" field.
+		RetryAttribute string
+		// CursorAttribute is the name of the payload attribute that
+		// receives the value of the incoming Last-Event-ID header so
+		// the endpoint implementation can resume the stream from
+		// there.
+		CursorAttribute string
+	}
+
+	// WebSocketExpr configures an endpoint exposed as a WebSocket
+	// connection.
+	WebSocketExpr struct {
+		// Endpoint is the parent endpoint.
+		Endpoint *EndpointExpr
+		// Subprotocols lists the WebSocket subprotocols the server
+		// accepts, in preference order.
+		Subprotocols []string
+		// PingInterval is how often the server sends a ping control
+		// frame to keep the connection alive, e.g. "30s".
+		PingInterval string
+		// MaxMessageSize is the largest message, in bytes, the server
+		// accepts from the client.
+		MaxMessageSize int
+		// Binary indicates messages are framed as binary instead of
+		// JSON text frames.
+		Binary bool
+	}
+)
+
+const (
+	// StreamKindNone means the endpoint uses a regular request/response
+	// exchange.
+	StreamKindNone StreamKind = iota
+	// StreamKindSSE means the endpoint is exposed as a Server-Sent
+	// Events stream.
+	StreamKindSSE
+	// StreamKindWebSocket means the endpoint is exposed as a WebSocket
+	// connection.
+	StreamKindWebSocket
+)
+
+// EvalName returns the generic definition name used in error messages.
+func (s *SSEExpr) EvalName() string {
+	if s.Endpoint != nil {
+		return s.Endpoint.EvalName() + " SSE stream"
+	}
+	return "SSE stream"
+}
+
+// Validate makes sure the SSE stream maps to an existing result attribute
+// when EventAttribute is set.
+func (s *SSEExpr) Validate() error {
+	if s.Endpoint == nil || s.Endpoint.MethodExpr == nil || s.Endpoint.MethodExpr.Result == nil {
+		return nil
+	}
+	obj := design.AsObject(s.Endpoint.MethodExpr.Result.Type)
+	if obj == nil {
+		return nil
+	}
+	for _, name := range []string{s.EventAttribute, s.IDAttribute, s.RetryAttribute} {
+		if name != "" && obj.Attribute(name) == nil {
+			return fmt.Errorf("%s: %q is not an attribute of the method result", s.EvalName(), name)
+		}
+	}
+	return nil
+}
+
+// EvalName returns the generic definition name used in error messages.
+func (w *WebSocketExpr) EvalName() string {
+	if w.Endpoint != nil {
+		return w.Endpoint.EvalName() + " WebSocket stream"
+	}
+	return "WebSocket stream"
+}
+
+// Validate makes sure the WebSocket configuration is consistent.
+func (w *WebSocketExpr) Validate() error {
+	if w.MaxMessageSize < 0 {
+		return fmt.Errorf("%s: MaxMessageSize cannot be negative", w.EvalName())
+	}
+	return nil
+}
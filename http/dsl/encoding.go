@@ -0,0 +1,95 @@
+package dsl
+
+import (
+	"goa.design/goa/eval"
+	httpdesign "goa.design/goa/http/design"
+)
+
+// Consumes registers one or more media types the API accepts in request
+// bodies, together with an optional Package option that identifies the Go
+// package implementing the decoder. When Package is omitted the media type
+// must be one of the built-ins ("application/json", "application/xml",
+// "application/msgpack", "application/x-protobuf" or
+// "application/x-ndjson").
+//
+// Consumes must appear in an API expression.
+//
+// Example:
+//
+//	API("calc", func() {
+//	    Consumes("application/json")
+//	    Consumes("application/msgpack", Package("github.com/vmihailenco/msgpack"))
+//	})
+func Consumes(mimeTypes ...interface{}) {
+	root, ok := eval.Current().(*httpdesign.RootExpr)
+	if !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	names, opt := splitEncodingArgs(mimeTypes)
+	d := &httpdesign.DecoderExpr{MIMETypes: names}
+	if opt != nil {
+		d.PackagePath = opt.PackagePath
+		d.Function = opt.Function
+	}
+	root.Decoders = append(root.Decoders, d)
+}
+
+// Produces registers one or more media types the API can encode response
+// bodies as, together with an optional Package option. See Consumes for
+// details on the Package option.
+//
+// Produces must appear in an API expression.
+func Produces(mimeTypes ...interface{}) {
+	root, ok := eval.Current().(*httpdesign.RootExpr)
+	if !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	names, opt := splitEncodingArgs(mimeTypes)
+	e := &httpdesign.EncoderExpr{MIMETypes: names}
+	if opt != nil {
+		e.PackagePath = opt.PackagePath
+		e.Function = opt.Function
+	}
+	if len(names) == 1 && names[0] == "application/x-ndjson" {
+		e.Streaming = true
+	}
+	root.Encoders = append(root.Encoders, e)
+}
+
+// encodingOption carries the Package DSL function result.
+type encodingOption struct {
+	PackagePath string
+	Function    string
+}
+
+// Package specifies the Go import path of the package that implements the
+// encoder or decoder for the media types passed to the enclosing Consumes
+// or Produces call. Function optionally overrides the name of the function
+// used to instantiate the codec, it defaults to "NewEncoder"/"NewDecoder".
+func Package(path string, fn ...string) *encodingOption {
+	opt := &encodingOption{PackagePath: path}
+	if len(fn) > 0 {
+		opt.Function = fn[0]
+	}
+	return opt
+}
+
+// splitEncodingArgs separates the media type names from the trailing
+// *encodingOption argument, if any, accepted by Consumes and Produces.
+func splitEncodingArgs(args []interface{}) ([]string, *encodingOption) {
+	var (
+		names []string
+		opt   *encodingOption
+	)
+	for _, a := range args {
+		switch t := a.(type) {
+		case string:
+			names = append(names, t)
+		case *encodingOption:
+			opt = t
+		}
+	}
+	return names, opt
+}
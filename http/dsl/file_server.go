@@ -0,0 +1,104 @@
+package dsl
+
+import (
+	"goa.design/goa/eval"
+	httpdesign "goa.design/goa/http/design"
+)
+
+// ETag sets the ETag generation mode used by the enclosing FileServerExpr. mode
+// must be one of the design.ETagMode constants: ETagStrong, ETagWeak or
+// ETagOff (the default).
+//
+// ETag must appear in a Files expression.
+func ETag(mode httpdesign.ETagMode) {
+	fs, ok := eval.Current().(*httpdesign.FileServerExpr)
+	if !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	fs.ETag = mode
+}
+
+// Ranges enables support for the Range request header on the enclosing
+// file server, allowing clients to request a subset of a file's bytes.
+//
+// Ranges must appear in a Files expression.
+func Ranges(enabled bool) {
+	fs, ok := eval.Current().(*httpdesign.FileServerExpr)
+	if !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	fs.Ranges = enabled
+}
+
+// PrecompressedEncodings lists the content codings the generated handler
+// looks for as pre-compressed siblings of a requested file, e.g. serving
+// "foo.js.gz" for a request to "foo.js" when the client's Accept-Encoding
+// header allows "gzip".
+//
+// PrecompressedEncodings must appear in a Files expression.
+func PrecompressedEncodings(encodings ...string) {
+	fs, ok := eval.Current().(*httpdesign.FileServerExpr)
+	if !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	fs.PrecompressedEncodings = encodings
+}
+
+// SPAFallback configures the enclosing file server to serve index, with a
+// 200 status, for any request that does not map to an existing file. This
+// supports single page applications that perform their own client-side
+// routing.
+//
+// SPAFallback must appear in a Files expression.
+func SPAFallback(index string) {
+	fs, ok := eval.Current().(*httpdesign.FileServerExpr)
+	if !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	fs.SPAFallback = index
+}
+
+// CacheControl sets the value of the Cache-Control header returned with
+// every response served by the enclosing file server.
+//
+// CacheControl must appear in a Files expression.
+func CacheControl(value string) {
+	fs, ok := eval.Current().(*httpdesign.FileServerExpr)
+	if !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	fs.CacheControl = value
+}
+
+// IndexNames lists the file names tried, in order, when a request maps to a
+// directory. Defaults to "index.html".
+//
+// IndexNames must appear in a Files expression.
+func IndexNames(names ...string) {
+	fs, ok := eval.Current().(*httpdesign.FileServerExpr)
+	if !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	fs.IndexNames = names
+}
+
+// Embed indicates that the generated code should bundle the file server's
+// FilePath into the resulting binary using a //go:embed directive and serve
+// it from an fs.FS, making the binary self-contained instead of reading
+// from the OS file system at runtime.
+//
+// Embed must appear in a Files expression.
+func Embed() {
+	fs, ok := eval.Current().(*httpdesign.FileServerExpr)
+	if !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	fs.Embed = true
+}
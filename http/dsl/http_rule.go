@@ -0,0 +1,110 @@
+package dsl
+
+import (
+	"goa.design/goa/eval"
+	httpdesign "goa.design/goa/http/design"
+)
+
+// HTTPRule describes how an endpoint is exposed over HTTP using the same
+// fields as Google's google.api.http annotation. It is meant to be used
+// alongside a gRPC transport definition so that a single method can be
+// served simultaneously over REST+JSON and gRPC.
+//
+// HTTPRule must appear in an Endpoint expression.
+//
+// HTTPRule accepts one argument: a function that uses the nested DSL
+// functions GET, PUT, POST, PATCH, DELETE, Body, ResponseBody and
+// AdditionalBinding to describe the rule.
+//
+// Example:
+//
+//	Endpoint("GetMessage", func() {
+//	    HTTPRule(func() {
+//	        GET("/v1/messages/{message_id}")
+//	        AdditionalBinding(func() {
+//	            GET("/v1/users/{user_id}/messages/{message_id}")
+//	        })
+//	    })
+//	})
+func HTTPRule(fn func()) {
+	e, ok := eval.Current().(*httpdesign.EndpointExpr)
+	if !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	rule := &httpdesign.HTTPRuleExpr{Endpoint: e}
+	if !eval.Execute(fn, rule) {
+		return
+	}
+	e.Rules = append(e.Rules, rule)
+}
+
+// GET sets the enclosing HTTPRule's method to "GET" and its pattern to
+// path.
+func GET(path string) { setRuleVerb("GET", path) }
+
+// PUT sets the enclosing HTTPRule's method to "PUT" and its pattern to
+// path.
+func PUT(path string) { setRuleVerb("PUT", path) }
+
+// POST sets the enclosing HTTPRule's method to "POST" and its pattern to
+// path.
+func POST(path string) { setRuleVerb("POST", path) }
+
+// PATCH sets the enclosing HTTPRule's method to "PATCH" and its pattern to
+// path.
+func PATCH(path string) { setRuleVerb("PATCH", path) }
+
+// DELETE sets the enclosing HTTPRule's method to "DELETE" and its pattern
+// to path.
+func DELETE(path string) { setRuleVerb("DELETE", path) }
+
+// setRuleVerb is the shared implementation of GET, PUT, POST, PATCH and
+// DELETE: it sets the HTTP method and pattern of the HTTPRuleExpr in scope.
+func setRuleVerb(method, path string) {
+	rule, ok := eval.Current().(*httpdesign.HTTPRuleExpr)
+	if !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	rule.Method = method
+	rule.Pattern = path
+}
+
+// Body sets the name of the request attribute that is used as the HTTP
+// request body. The special value "*" maps the whole payload to the body.
+func Body(attr string) {
+	rule, ok := eval.Current().(*httpdesign.HTTPRuleExpr)
+	if !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	rule.Body = attr
+}
+
+// ResponseBody sets the name of the result attribute that is used as the
+// HTTP response body. The special value "*" maps the whole result to the
+// body.
+func ResponseBody(attr string) {
+	rule, ok := eval.Current().(*httpdesign.HTTPRuleExpr)
+	if !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	rule.ResponseBody = attr
+}
+
+// AdditionalBinding defines an alternate HTTP rule that routes to the same
+// endpoint as the enclosing HTTPRule.
+func AdditionalBinding(fn func()) {
+	parent, ok := eval.Current().(*httpdesign.HTTPRuleExpr)
+	if !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	rule := &httpdesign.HTTPRuleExpr{Endpoint: parent.Endpoint}
+	if !eval.Execute(fn, rule) {
+		return
+	}
+	parent.AdditionalBindings = append(parent.AdditionalBindings, rule)
+}
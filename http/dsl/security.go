@@ -0,0 +1,144 @@
+package dsl
+
+import (
+	"goa.design/goa/eval"
+	httpdesign "goa.design/goa/http/design"
+)
+
+// APIKey defines an API key security scheme, identified by name, where the
+// key is carried in the request header named fieldName. The scheme's In is
+// always "header"; keys carried in a query string parameter or cookie cannot
+// be declared with APIKey.
+//
+// APIKey must appear in an API expression. The returned scheme is
+// referenced from endpoints using Security.
+//
+// Example:
+//
+//	var APIKeyAuth = APIKey("api_key", "X-API-Key", func() {
+//	    Description("Secret key used to access the API")
+//	})
+func APIKey(name, fieldName string, fn ...func()) *httpdesign.SecuritySchemeExpr {
+	return registerScheme(name, httpdesign.APIKeyKind, func(s *httpdesign.SecuritySchemeExpr) {
+		s.In = "header"
+		s.Name = fieldName
+	}, fn...)
+}
+
+// BasicAuth defines a security scheme using HTTP Basic authentication.
+func BasicAuth(name string, fn ...func()) *httpdesign.SecuritySchemeExpr {
+	return registerScheme(name, httpdesign.BasicAuthKind, nil, fn...)
+}
+
+// JWT defines a security scheme that authenticates requests using a signed
+// JSON Web Token. jwksURL is the JWKS endpoint the generated middleware
+// refreshes its signing keys from.
+func JWT(name, jwksURL string, fn ...func()) *httpdesign.SecuritySchemeExpr {
+	return registerScheme(name, httpdesign.JWTKind, func(s *httpdesign.SecuritySchemeExpr) {
+		s.JWKSURL = jwksURL
+		s.JWKSRefreshInterval = "15m"
+	}, fn...)
+}
+
+// OAuth2 defines a security scheme supporting one or more OAuth2 flows, see
+// AuthorizationCodeFlow and ClientCredentialsFlow.
+func OAuth2(name string, fn ...func()) *httpdesign.SecuritySchemeExpr {
+	return registerScheme(name, httpdesign.OAuth2Kind, nil, fn...)
+}
+
+// AuthorizationCodeFlow declares the OAuth2 authorization-code flow for the
+// enclosing OAuth2 scheme.
+func AuthorizationCodeFlow(authorizationURL, tokenURL string) {
+	addFlow(&httpdesign.OAuth2FlowExpr{
+		Kind:             "authorization_code",
+		AuthorizationURL: authorizationURL,
+		TokenURL:         tokenURL,
+	})
+}
+
+// ClientCredentialsFlow declares the OAuth2 client-credentials flow for the
+// enclosing OAuth2 scheme.
+func ClientCredentialsFlow(tokenURL string) {
+	addFlow(&httpdesign.OAuth2FlowExpr{Kind: "client_credentials", TokenURL: tokenURL})
+}
+
+// Scope has two uses depending on the enclosing expression:
+//
+//   - in a JWT or OAuth2 security scheme, Scope(name, description) declares
+//     a scope the scheme recognizes.
+//   - in a Security requirement, Scope(name) requires the caller's token to
+//     carry that scope; description is ignored there.
+func Scope(name string, description ...string) {
+	switch s := eval.Current().(type) {
+	case *httpdesign.SecuritySchemeExpr:
+		if s.Scopes == nil {
+			s.Scopes = make(map[string]string)
+		}
+		var desc string
+		if len(description) > 0 {
+			desc = description[0]
+		}
+		s.Scopes[name] = desc
+	case *httpdesign.SecurityExpr:
+		s.Scopes = append(s.Scopes, name)
+	default:
+		eval.IncompatibleDSL()
+	}
+}
+
+// Security lists the security schemes, and the scopes required from them,
+// that an endpoint accepts. The endpoint is accessible to a caller that
+// satisfies any single requirement; use multiple Security calls to express
+// alternative ways to authenticate.
+//
+// Security must appear in an Endpoint expression.
+//
+// Example:
+//
+//	Endpoint("Secret", func() {
+//	    Security(JWTAuth, func() {
+//	        Scope("api:read")
+//	    })
+//	})
+func Security(scheme *httpdesign.SecuritySchemeExpr, fn ...func()) {
+	e, ok := eval.Current().(*httpdesign.EndpointExpr)
+	if !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	req := &httpdesign.SecurityExpr{Endpoint: e, Schemes: []*httpdesign.SecuritySchemeExpr{scheme}}
+	if len(fn) > 0 {
+		if !eval.Execute(fn[0], req) {
+			return
+		}
+	}
+	e.Security = append(e.Security, req)
+}
+
+func registerScheme(name string, kind httpdesign.SecuritySchemeKind, defaults func(*httpdesign.SecuritySchemeExpr), fn ...func()) *httpdesign.SecuritySchemeExpr {
+	root, ok := eval.Current().(*httpdesign.RootExpr)
+	if !ok {
+		eval.IncompatibleDSL()
+		return nil
+	}
+	s := &httpdesign.SecuritySchemeExpr{SchemeName: name, Kind: kind}
+	if defaults != nil {
+		defaults(s)
+	}
+	if len(fn) > 0 {
+		if !eval.Execute(fn[0], s) {
+			return s
+		}
+	}
+	root.SecuritySchemes = append(root.SecuritySchemes, s)
+	return s
+}
+
+func addFlow(flow *httpdesign.OAuth2FlowExpr) {
+	s, ok := eval.Current().(*httpdesign.SecuritySchemeExpr)
+	if !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	s.Flows = append(s.Flows, flow)
+}
@@ -0,0 +1,28 @@
+package dsl
+
+import (
+	"testing"
+
+	"goa.design/goa/eval"
+	httpdesign "goa.design/goa/http/design"
+)
+
+func TestScopeOnSecuritySchemeDeclaresScope(t *testing.T) {
+	s := &httpdesign.SecuritySchemeExpr{SchemeName: "jwt", Kind: httpdesign.JWTKind}
+	if !eval.Execute(func() { Scope("api:read", "read access") }, s) {
+		t.Fatal("eval.Execute failed")
+	}
+	if got, want := s.Scopes["api:read"], "read access"; got != want {
+		t.Fatalf("got scope description %q, want %q", got, want)
+	}
+}
+
+func TestScopeOnSecurityRequirementRequiresScope(t *testing.T) {
+	req := &httpdesign.SecurityExpr{}
+	if !eval.Execute(func() { Scope("api:read") }, req) {
+		t.Fatal("eval.Execute failed")
+	}
+	if len(req.Scopes) != 1 || req.Scopes[0] != "api:read" {
+		t.Fatalf("got scopes %v, want [api:read]", req.Scopes)
+	}
+}
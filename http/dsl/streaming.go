@@ -0,0 +1,143 @@
+package dsl
+
+import (
+	"goa.design/goa/eval"
+	httpdesign "goa.design/goa/http/design"
+)
+
+// SSE configures the enclosing endpoint to be served as a Server-Sent
+// Events stream instead of a regular request/response exchange.
+//
+// SSE must appear in an Endpoint expression.
+//
+// Example:
+//
+//	Endpoint("Watch", func() {
+//	    SSE(func() {
+//	        EventData("event")
+//	        EventID("id")
+//	        Cursor("last_event_id")
+//	    })
+//	})
+func SSE(fn ...func()) {
+	e, ok := eval.Current().(*httpdesign.EndpointExpr)
+	if !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	sse := &httpdesign.SSEExpr{Endpoint: e}
+	if len(fn) > 0 {
+		if !eval.Execute(fn[0], sse) {
+			return
+		}
+	}
+	e.SSE = sse
+}
+
+// EventData sets the name of the result attribute encoded in the SSE "data"
+// field, defaulting to the whole result when unset.
+func EventData(attr string) {
+	sse, ok := eval.Current().(*httpdesign.SSEExpr)
+	if !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	sse.EventAttribute = attr
+}
+
+// EventID sets the name of the result attribute encoded in the SSE "id"
+// field.
+func EventID(attr string) {
+	sse, ok := eval.Current().(*httpdesign.SSEExpr)
+	if !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	sse.IDAttribute = attr
+}
+
+// EventRetry sets the name of the result attribute encoded in the SSE
+// "retry" field.
+func EventRetry(attr string) {
+	sse, ok := eval.Current().(*httpdesign.SSEExpr)
+	if !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	sse.RetryAttribute = attr
+}
+
+// Cursor sets the name of the payload attribute that receives the value of
+// an incoming Last-Event-ID header, letting the endpoint implementation
+// resume the stream where the client left off.
+func Cursor(attr string) {
+	sse, ok := eval.Current().(*httpdesign.SSEExpr)
+	if !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	sse.CursorAttribute = attr
+}
+
+// WebSocket configures the enclosing endpoint to be served over a
+// WebSocket connection instead of a regular request/response exchange.
+//
+// WebSocket must appear in an Endpoint expression.
+func WebSocket(fn ...func()) {
+	e, ok := eval.Current().(*httpdesign.EndpointExpr)
+	if !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	ws := &httpdesign.WebSocketExpr{Endpoint: e}
+	if len(fn) > 0 {
+		if !eval.Execute(fn[0], ws) {
+			return
+		}
+	}
+	e.WebSocket = ws
+}
+
+// Subprotocols lists the WebSocket subprotocols the enclosing endpoint
+// accepts, in preference order.
+func Subprotocols(protos ...string) {
+	ws, ok := eval.Current().(*httpdesign.WebSocketExpr)
+	if !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	ws.Subprotocols = protos
+}
+
+// PingInterval sets how often the server sends a WebSocket ping control
+// frame to keep the connection alive, e.g. "30s".
+func PingInterval(interval string) {
+	ws, ok := eval.Current().(*httpdesign.WebSocketExpr)
+	if !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	ws.PingInterval = interval
+}
+
+// MaxMessageSize sets the largest WebSocket message, in bytes, the server
+// accepts from the client.
+func MaxMessageSize(size int) {
+	ws, ok := eval.Current().(*httpdesign.WebSocketExpr)
+	if !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	ws.MaxMessageSize = size
+}
+
+// BinaryFrames configures the enclosing WebSocket endpoint to frame
+// messages as binary instead of the default JSON text frames.
+func BinaryFrames() {
+	ws, ok := eval.Current().(*httpdesign.WebSocketExpr)
+	if !ok {
+		eval.IncompatibleDSL()
+		return
+	}
+	ws.Binary = true
+}
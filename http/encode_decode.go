@@ -0,0 +1,235 @@
+package http
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+type (
+	// Decoder decodes a request body into a Go value.
+	Decoder interface {
+		Decode(v interface{}) error
+	}
+
+	// Encoder encodes a Go value into a response body.
+	Encoder interface {
+		Encode(v interface{}) error
+	}
+
+	// StreamingEncoder is implemented by encoders that can write more
+	// than one value to the underlying io.Writer without buffering the
+	// whole result set in memory, e.g. the built-in "application/x-ndjson"
+	// encoder.
+	StreamingEncoder interface {
+		Encoder
+		// Flush writes any buffered data to the underlying writer.
+		Flush() error
+	}
+
+	// DecoderFunc instantiates a Decoder that reads from r.
+	DecoderFunc func(r io.Reader) Decoder
+
+	// EncoderFunc instantiates an Encoder that writes to w.
+	EncoderFunc func(w io.Writer) Encoder
+)
+
+// decoders and encoders are indexed by media type. JSON, XML and the
+// streaming NDJSON encoder are wired in directly below. "application/msgpack"
+// and "application/x-protobuf" are also recognized as built-ins by the
+// design (see design.IsBuiltinMediaType) but this package has no codec for
+// them: they get one only when a Consumes or Produces call names a Package,
+// which the generated RegisterEncodings function registers on startup.
+// Otherwise RequestDecoder and ResponseEncoder fall back to JSON for them.
+var (
+	decoders = map[string]DecoderFunc{
+		"application/json": func(r io.Reader) Decoder { return json.NewDecoder(r) },
+		"application/xml":  func(r io.Reader) Decoder { return xml.NewDecoder(r) },
+	}
+	encoders = map[string]EncoderFunc{
+		"application/json": func(w io.Writer) Encoder { return json.NewEncoder(w) },
+		"application/xml":  func(w io.Writer) Encoder { return xml.NewEncoder(w) },
+		"application/x-ndjson": func(w io.Writer) Encoder {
+			bw := bufio.NewWriter(w)
+			return &ndjsonEncoder{w: bw, enc: json.NewEncoder(bw)}
+		},
+	}
+)
+
+// RegisterDecoder associates mime with a decoder constructor, overriding any
+// previous registration. Generated code calls this once on startup for
+// every media type registered via a Consumes call with a Package option.
+func RegisterDecoder(mime string, fn DecoderFunc) { decoders[mime] = fn }
+
+// RegisterEncoder associates mime with an encoder constructor, overriding
+// any previous registration. Generated code calls this once on startup for
+// every media type registered via a Produces call with a Package option.
+func RegisterEncoder(mime string, fn EncoderFunc) { encoders[mime] = fn }
+
+// RequestDecoder returns the decoder to use to read the body of r, selected
+// using the request's Content-Type header. It defaults to the JSON decoder
+// if the header is missing or doesn't match any registered decoder.
+func RequestDecoder(r *http.Request) Decoder {
+	ct := r.Header.Get("Content-Type")
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		mt = "application/json"
+	}
+	fn, ok := decoders[mt]
+	if !ok {
+		fn = decoders["application/json"]
+	}
+	return fn(r.Body)
+}
+
+// ResponseEncoder returns the encoder to use to write the response body for
+// r, selected by negotiating the request's Accept header (including
+// quality values) against the media types passed in available. When
+// available is empty every registered encoder is considered. It also
+// returns the negotiated media type so the caller can set the
+// Content-Type header. It defaults to JSON when negotiation fails to find a
+// match.
+func ResponseEncoder(w io.Writer, r *http.Request, available ...string) (Encoder, string) {
+	if len(available) == 0 {
+		for mt := range encoders {
+			available = append(available, mt)
+		}
+		sort.Strings(available)
+	}
+	mt := NegotiateContentType(r.Header.Get("Accept"), available)
+	if mt == "" {
+		mt = "application/json"
+	}
+	fn, ok := encoders[mt]
+	if !ok {
+		fn = encoders["application/json"]
+		mt = "application/json"
+	}
+	return fn(w), mt
+}
+
+// NegotiateContentType implements the HTTP Accept header content
+// negotiation algorithm (RFC 7231 section 5.3.2): it parses accept into a
+// list of media ranges with their quality value and returns the entry in
+// available with the highest combined specificity/quality score, or "" if
+// none of the available media types is acceptable.
+func NegotiateContentType(accept string, available []string) string {
+	if accept == "" {
+		if len(available) == 0 {
+			return ""
+		}
+		return available[0]
+	}
+	ranges := parseAccept(accept)
+	best := ""
+	bestScore := -1.0
+	bestSpecificity := -1
+	for _, mt := range available {
+		for _, rg := range ranges {
+			if rg.q <= 0 {
+				continue
+			}
+			if !rg.matches(mt) {
+				continue
+			}
+			specificity := rg.specificity()
+			if rg.q > bestScore || (rg.q == bestScore && specificity > bestSpecificity) {
+				best = mt
+				bestScore = rg.q
+				bestSpecificity = specificity
+			}
+		}
+	}
+	return best
+}
+
+// acceptRange is a single entry of an Accept header, e.g.
+// "application/json;q=0.8".
+type acceptRange struct {
+	typ, subtyp string
+	q           float64
+}
+
+func (a acceptRange) matches(mt string) bool {
+	typ, subtyp, ok := splitMediaType(mt)
+	if !ok {
+		return false
+	}
+	if a.typ != "*" && a.typ != typ {
+		return false
+	}
+	if a.subtyp != "*" && a.subtyp != subtyp {
+		return false
+	}
+	return true
+}
+
+// specificity ranks "type/subtype" above "type/*" above "*/*" so an exact
+// match wins over a wildcard with the same quality value.
+func (a acceptRange) specificity() int {
+	switch {
+	case a.typ != "*" && a.subtyp != "*":
+		return 2
+	case a.typ != "*":
+		return 1
+	default:
+		return 0
+	}
+}
+
+func splitMediaType(mt string) (string, string, bool) {
+	parts := strings.SplitN(mt, "/", 2)
+	if len(parts) != 2 {
+		return "", "", false
+	}
+	return parts[0], parts[1], true
+}
+
+func parseAccept(accept string) []acceptRange {
+	var ranges []acceptRange
+	for _, part := range strings.Split(accept, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		segs := strings.Split(part, ";")
+		typ, subtyp, ok := splitMediaType(strings.TrimSpace(segs[0]))
+		if !ok {
+			continue
+		}
+		q := 1.0
+		for _, param := range segs[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64); err == nil {
+					q = v
+				}
+			}
+		}
+		ranges = append(ranges, acceptRange{typ: typ, subtyp: subtyp, q: q})
+	}
+	return ranges
+}
+
+// ndjsonEncoder implements StreamingEncoder for "application/x-ndjson": each
+// call to Encode writes one JSON value followed by a newline so a service
+// can push a large result set to the client without buffering it.
+type ndjsonEncoder struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (e *ndjsonEncoder) Encode(v interface{}) error {
+	if err := e.enc.Encode(v); err != nil {
+		return err
+	}
+	return e.w.Flush()
+}
+
+func (e *ndjsonEncoder) Flush() error { return e.w.Flush() }
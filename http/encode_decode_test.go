@@ -0,0 +1,51 @@
+package http
+
+import "testing"
+
+func TestNegotiateContentType(t *testing.T) {
+	cases := []struct {
+		name      string
+		accept    string
+		available []string
+		want      string
+	}{
+		{
+			name:      "no accept header picks first available",
+			accept:    "",
+			available: []string{"application/json", "application/xml"},
+			want:      "application/json",
+		},
+		{
+			name:      "exact match wins over wildcard at equal quality",
+			accept:    "text/*;q=0.8, application/json;q=0.8",
+			available: []string{"application/json", "text/plain"},
+			want:      "application/json",
+		},
+		{
+			name:      "q=0 excludes an otherwise matching entry",
+			accept:    "application/json;q=0",
+			available: []string{"application/json"},
+			want:      "",
+		},
+		{
+			name:      "q=0 on one type falls back to another acceptable type",
+			accept:    "application/json;q=0, application/xml;q=0.5",
+			available: []string{"application/json", "application/xml"},
+			want:      "application/xml",
+		},
+		{
+			name:      "no overlap returns empty",
+			accept:    "application/pdf",
+			available: []string{"application/json"},
+			want:      "",
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			got := NegotiateContentType(c.accept, c.available)
+			if got != c.want {
+				t.Errorf("NegotiateContentType(%q, %v) = %q, want %q", c.accept, c.available, got, c.want)
+			}
+		})
+	}
+}
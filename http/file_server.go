@@ -0,0 +1,208 @@
+package http
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// FileServerConfig holds the options controlling the behavior of the
+// handler returned by NewFileServer. It mirrors the fields of the
+// design.FileServerExpr used to generate it.
+type FileServerConfig struct {
+	// FS is the file system the handler serves files from, either an
+	// os.DirFS rooted at FilePath or an embed.FS when Embed is set in
+	// the design.
+	FS fs.FS
+	// ETag controls ETag generation, one of "off", "strong" or "weak".
+	ETag string
+	// Ranges enables Range request support.
+	Ranges bool
+	// PrecompressedEncodings lists the content codings to look for as
+	// pre-compressed siblings of a requested file, in preference order.
+	PrecompressedEncodings []string
+	// SPAFallback is the name of the file served for paths that don't
+	// match an existing file, empty disables the fallback.
+	SPAFallback string
+	// CacheControl is the Cache-Control header value, empty omits the
+	// header.
+	CacheControl string
+	// IndexNames lists the file names tried when a request maps to a
+	// directory.
+	IndexNames []string
+}
+
+// NewFileServer returns an http.Handler that serves the files in cfg.FS
+// honoring ETag/If-None-Match, Range, pre-compressed encodings and SPA
+// fallback according to cfg.
+func NewFileServer(cfg FileServerConfig) http.Handler {
+	if len(cfg.IndexNames) == 0 {
+		cfg.IndexNames = []string{"index.html"}
+	}
+	return &fileServer{cfg: cfg}
+}
+
+type fileServer struct{ cfg FileServerConfig }
+
+func (s *fileServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if name == "" || name == "." {
+		name = s.cfg.IndexNames[0]
+	}
+	f, info, err := s.open(name)
+	if err != nil {
+		if s.cfg.SPAFallback != "" {
+			f, info, err = s.open(s.cfg.SPAFallback)
+			name = s.cfg.SPAFallback
+		}
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+	}
+	defer f.Close()
+
+	if info.IsDir() {
+		for _, idx := range s.cfg.IndexNames {
+			if f2, info2, err2 := s.open(path.Join(name, idx)); err2 == nil {
+				f.Close()
+				f, info, err = f2, info2, nil
+				name = path.Join(name, idx)
+				break
+			}
+		}
+		if info.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+	}
+
+	encoding, rc, size := s.selectEncoding(r, name, f, info)
+	if rc != f {
+		defer rc.Close()
+	}
+
+	if s.cfg.CacheControl != "" {
+		w.Header().Set("Cache-Control", s.cfg.CacheControl)
+	}
+	if encoding != "" {
+		w.Header().Set("Content-Encoding", encoding)
+		w.Header().Add("Vary", "Accept-Encoding")
+	}
+	if ct := mime.TypeByExtension(filepath.Ext(name)); ct != "" {
+		w.Header().Set("Content-Type", ct)
+	}
+
+	etag := s.etagFor(name, info)
+	if etag != "" {
+		w.Header().Set("ETag", etag)
+		if match := r.Header.Get("If-None-Match"); match != "" && etagMatches(match, etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	} else if ims := r.Header.Get("If-Modified-Since"); ims != "" {
+		if t, err := http.ParseTime(ims); err == nil && !info.ModTime().After(t) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	w.Header().Set("Last-Modified", info.ModTime().UTC().Format(http.TimeFormat))
+
+	if s.cfg.Ranges && encoding == "" {
+		if rs, ok := rc.(io.ReadSeeker); ok {
+			w.Header().Set("Accept-Ranges", "bytes")
+			http.ServeContent(w, r, name, info.ModTime(), rs)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Length", strconv.FormatInt(size, 10))
+	w.WriteHeader(http.StatusOK)
+	io.Copy(w, rc)
+}
+
+// open returns the file and its FileInfo for name.
+func (s *fileServer) open(name string) (fs.File, fs.FileInfo, error) {
+	f, err := s.cfg.FS.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, nil, err
+	}
+	return f, info, nil
+}
+
+// selectEncoding looks for a pre-compressed sibling of name that the client
+// accepts, returning the coding used (or "" for the original file), the
+// file to read from and its size. The returned fs.File may differ from f,
+// in which case the caller is responsible for closing both.
+func (s *fileServer) selectEncoding(r *http.Request, name string, f fs.File, info fs.FileInfo) (string, fs.File, int64) {
+	accept := r.Header.Get("Accept-Encoding")
+	for _, enc := range s.cfg.PrecompressedEncodings {
+		ext := precompressedExt(enc)
+		if ext == "" || !strings.Contains(accept, enc) {
+			continue
+		}
+		if cf, cinfo, err := s.open(name + ext); err == nil {
+			return enc, cf, cinfo.Size()
+		}
+	}
+	return "", f, info.Size()
+}
+
+func precompressedExt(encoding string) string {
+	switch encoding {
+	case "gzip":
+		return ".gz"
+	case "br":
+		return ".br"
+	default:
+		return ""
+	}
+}
+
+// etagFor computes the ETag header value for name according to cfg.ETag.
+func (s *fileServer) etagFor(name string, info fs.FileInfo) string {
+	switch s.cfg.ETag {
+	case "strong":
+		f, err := s.cfg.FS.Open(name)
+		if err != nil {
+			return ""
+		}
+		defer f.Close()
+		h := sha256.New()
+		if _, err := io.Copy(h, f); err != nil {
+			return ""
+		}
+		return `"` + hex.EncodeToString(h.Sum(nil))[:32] + `"`
+	case "weak":
+		return fmt.Sprintf(`W/"%x-%x"`, info.ModTime().Unix(), info.Size())
+	default:
+		return ""
+	}
+}
+
+// etagMatches reports whether header, a comma-separated If-None-Match
+// value (possibly "*"), matches etag.
+func etagMatches(header, etag string) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+	for _, tag := range strings.Split(header, ",") {
+		if strings.TrimSpace(tag) == etag {
+			return true
+		}
+	}
+	return false
+}
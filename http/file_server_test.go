@@ -0,0 +1,66 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFileServerSPAFallbackSetsContentTypeAndETag(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html": &fstest.MapFile{Data: []byte("<html></html>")},
+	}
+	h := NewFileServer(FileServerConfig{
+		FS:          fsys,
+		ETag:        "strong",
+		SPAFallback: "index.html",
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/some/client/route", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct == "" {
+		t.Fatal("expected a Content-Type header derived from the SPA fallback file, got none")
+	}
+	if etag := rec.Header().Get("ETag"); etag == "" {
+		t.Fatal("expected a strong ETag derived from the SPA fallback file, got none")
+	}
+}
+
+func TestFileServerServesExistingFile(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app.js": &fstest.MapFile{Data: []byte("console.log(1)")},
+	}
+	h := NewFileServer(FileServerConfig{FS: fsys})
+
+	req := httptest.NewRequest(http.MethodGet, "/app.js", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct == "" {
+		t.Fatal("expected a Content-Type header, got none")
+	}
+}
+
+func TestFileServerNotFoundWithoutFallback(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app.js": &fstest.MapFile{Data: []byte("console.log(1)")},
+	}
+	h := NewFileServer(FileServerConfig{FS: fsys})
+
+	req := httptest.NewRequest(http.MethodGet, "/missing", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,358 @@
+package http
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	_ "crypto/sha256" // register crypto.SHA256 used by hashForAlg
+	_ "crypto/sha512" // register crypto.SHA384 and crypto.SHA512 used by hashForAlg
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// claimsKey is the context key JWT middleware uses to store the
+	// validated claims.
+	claimsKey struct{}
+
+	// Claims is the set of standard and custom claims extracted from a
+	// validated JWT.
+	Claims map[string]interface{}
+
+	// Authenticator validates the credentials carried by r and returns
+	// the context to use for the remainder of the request, or an error
+	// if the credentials are missing or invalid. Generated security
+	// chains call one Authenticator per scheme in a security requirement
+	// and report the first error back to the caller instead of writing
+	// a response directly, so it can be routed through the endpoint's
+	// HTTPErrors like any other error.
+	Authenticator func(ctx context.Context, r *http.Request) (context.Context, error)
+
+	// JWTMiddlewareConfig configures NewJWTMiddleware.
+	JWTMiddlewareConfig struct {
+		// JWKSURL is the URL the middleware fetches signing keys from.
+		JWKSURL string
+		// RefreshInterval is how often the key set is refreshed,
+		// defaults to 15 minutes.
+		RefreshInterval time.Duration
+		// Issuer, if set, must match the token's "iss" claim.
+		Issuer string
+		// Audience, if set, must appear in the token's "aud" claim.
+		Audience string
+	}
+
+	// APIKeyMiddlewareConfig configures NewAPIKeyMiddleware.
+	APIKeyMiddlewareConfig struct {
+		// In is where the key is carried: "header", "query" or
+		// "cookie".
+		In string
+		// Name is the header, query string parameter or cookie name
+		// that carries the key.
+		Name string
+		// Validate is called with the extracted key and returns the
+		// context to use for the remainder of the request, or an
+		// error if the key is invalid.
+		Validate func(ctx context.Context, key string) (context.Context, error)
+	}
+
+	// BasicAuthMiddlewareConfig configures NewBasicAuthMiddleware.
+	BasicAuthMiddlewareConfig struct {
+		// Validate is called with the request's basic auth
+		// credentials and returns the context to use for the
+		// remainder of the request, or an error if they are invalid.
+		Validate func(ctx context.Context, user, pass string) (context.Context, error)
+	}
+
+	// OAuth2MiddlewareConfig configures NewOAuth2Middleware.
+	OAuth2MiddlewareConfig struct {
+		// Validate is called with the bearer token carried in the
+		// request's Authorization header and returns the context to
+		// use for the remainder of the request, or an error if the
+		// token is invalid.
+		Validate func(ctx context.Context, token string) (context.Context, error)
+	}
+
+	// jwks is a minimal JSON Web Key Set client that periodically
+	// refreshes its keys from a JWKSURL in the background.
+	jwks struct {
+		url      string
+		interval time.Duration
+
+		mu   sync.RWMutex
+		keys map[string]*rsa.PublicKey
+	}
+)
+
+// ClaimsFromContext returns the claims injected by the JWT middleware, if
+// any.
+func ClaimsFromContext(ctx context.Context) (Claims, bool) {
+	c, ok := ctx.Value(claimsKey{}).(Claims)
+	return c, ok
+}
+
+// NewJWTMiddleware returns an Authenticator that validates the bearer token
+// carried in the request's Authorization header against the signing keys
+// published at cfg.JWKSURL, checks the "iss", "aud", "exp" and "nbf" claims
+// and, on success, injects the token claims into the returned context under
+// a typed key retrievable with ClaimsFromContext.
+func NewJWTMiddleware(cfg JWTMiddlewareConfig) Authenticator {
+	if cfg.RefreshInterval == 0 {
+		cfg.RefreshInterval = 15 * time.Minute
+	}
+	ks := newJWKS(cfg.JWKSURL, cfg.RefreshInterval)
+	return func(ctx context.Context, r *http.Request) (context.Context, error) {
+		token, err := bearerToken(r)
+		if err != nil {
+			return nil, err
+		}
+		claims, err := ks.validate(token, cfg.Issuer, cfg.Audience)
+		if err != nil {
+			return nil, err
+		}
+		return context.WithValue(ctx, claimsKey{}, claims), nil
+	}
+}
+
+// NewAPIKeyMiddleware returns an Authenticator that extracts the key
+// carried in the request's cfg.In location under cfg.Name and delegates to
+// cfg.Validate.
+func NewAPIKeyMiddleware(cfg APIKeyMiddlewareConfig) Authenticator {
+	return func(ctx context.Context, r *http.Request) (context.Context, error) {
+		key, err := apiKeyFromRequest(r, cfg.In, cfg.Name)
+		if err != nil {
+			return nil, err
+		}
+		return cfg.Validate(ctx, key)
+	}
+}
+
+// apiKeyFromRequest extracts the API key carried in r at the given location
+// (one of "header", "query" or "cookie") under name.
+func apiKeyFromRequest(r *http.Request, in, name string) (string, error) {
+	switch in {
+	case "header":
+		if v := r.Header.Get(name); v != "" {
+			return v, nil
+		}
+	case "query":
+		if v := r.URL.Query().Get(name); v != "" {
+			return v, nil
+		}
+	case "cookie":
+		if c, err := r.Cookie(name); err == nil {
+			return c.Value, nil
+		}
+	}
+	return "", fmt.Errorf("missing API key %q in %s", name, in)
+}
+
+// NewBasicAuthMiddleware returns an Authenticator that extracts the
+// request's HTTP Basic auth credentials and delegates to cfg.Validate.
+func NewBasicAuthMiddleware(cfg BasicAuthMiddlewareConfig) Authenticator {
+	return func(ctx context.Context, r *http.Request) (context.Context, error) {
+		user, pass, ok := r.BasicAuth()
+		if !ok {
+			return nil, errors.New("missing basic auth credentials")
+		}
+		return cfg.Validate(ctx, user, pass)
+	}
+}
+
+// NewOAuth2Middleware returns an Authenticator that extracts the bearer
+// token carried in the request's Authorization header and delegates to
+// cfg.Validate.
+func NewOAuth2Middleware(cfg OAuth2MiddlewareConfig) Authenticator {
+	return func(ctx context.Context, r *http.Request) (context.Context, error) {
+		token, err := bearerToken(r)
+		if err != nil {
+			return nil, err
+		}
+		return cfg.Validate(ctx, token)
+	}
+}
+
+func bearerToken(r *http.Request) (string, error) {
+	auth := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if !strings.HasPrefix(auth, prefix) {
+		return "", errors.New("missing bearer token")
+	}
+	return strings.TrimPrefix(auth, prefix), nil
+}
+
+func newJWKS(url string, interval time.Duration) *jwks {
+	k := &jwks{url: url, interval: interval, keys: map[string]*rsa.PublicKey{}}
+	k.refresh()
+	go k.refreshLoop()
+	return k
+}
+
+func (k *jwks) refreshLoop() {
+	ticker := time.NewTicker(k.interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		k.refresh()
+	}
+}
+
+// refresh fetches the key set from k.url. Errors are swallowed: the
+// middleware keeps using the last successfully fetched keys until the next
+// refresh succeeds.
+func (k *jwks) refresh() {
+	resp, err := http.Get(k.url)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	var set struct {
+		Keys []struct {
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return
+	}
+	keys := make(map[string]*rsa.PublicKey, len(set.Keys))
+	for _, jwk := range set.Keys {
+		key, err := rsaPublicKeyFromJWK(jwk.N, jwk.E)
+		if err != nil {
+			continue
+		}
+		keys[jwk.Kid] = key
+	}
+	k.mu.Lock()
+	k.keys = keys
+	k.mu.Unlock()
+}
+
+func (k *jwks) key(kid string) (*rsa.PublicKey, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	key, ok := k.keys[kid]
+	return key, ok
+}
+
+// validate verifies the token's signature against the key published for its
+// "kid" and checks the iss/aud/exp/nbf claims, returning the decoded claim
+// set on success. Only RSA-signed tokens ("RS256", "RS384" and "RS512") are
+// supported, matching the keys a JWKS document publishes.
+func (k *jwks) validate(token, issuer, audience string) (Claims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	key, ok := k.key(header.Kid)
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", header.Kid)
+	}
+	hash, err := hashForAlg(header.Alg)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature encoding: %w", err)
+	}
+	h := hash.New()
+	h.Write([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(key, hash, h.Sum(nil), sig); err != nil {
+		return nil, fmt.Errorf("invalid token signature: %w", err)
+	}
+
+	var claims Claims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	if exp, ok := claims["exp"].(float64); ok && int64(exp) < now {
+		return nil, errors.New("token is expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && int64(nbf) > now {
+		return nil, errors.New("token is not yet valid")
+	}
+	if issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != issuer {
+			return nil, fmt.Errorf("unexpected issuer %q", iss)
+		}
+	}
+	if audience != "" && !audienceContains(claims["aud"], audience) {
+		return nil, fmt.Errorf("audience does not contain %q", audience)
+	}
+	return claims, nil
+}
+
+// hashForAlg returns the crypto.Hash corresponding to a JWT "alg" header
+// value, restricted to the RSA PKCS#1 v1.5 algorithms whose keys a JWKS
+// document publishes.
+func hashForAlg(alg string) (crypto.Hash, error) {
+	switch alg {
+	case "RS256":
+		return crypto.SHA256, nil
+	case "RS384":
+		return crypto.SHA384, nil
+	case "RS512":
+		return crypto.SHA512, nil
+	default:
+		return 0, fmt.Errorf("unsupported signing algorithm %q", alg)
+	}
+}
+
+func audienceContains(aud interface{}, want string) bool {
+	switch v := aud.(type) {
+	case string:
+		return v == want
+	case []interface{}:
+		for _, a := range v {
+			if s, ok := a.(string); ok && s == want {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func decodeSegment(seg string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(seg)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+// rsaPublicKeyFromJWK builds an *rsa.PublicKey from the base64url encoded
+// modulus (n) and exponent (e) fields of a JSON Web Key.
+func rsaPublicKeyFromJWK(n, e string) (*rsa.PublicKey, error) {
+	nb, err := base64.RawURLEncoding.DecodeString(n)
+	if err != nil {
+		return nil, err
+	}
+	eb, err := base64.RawURLEncoding.DecodeString(e)
+	if err != nil {
+		return nil, err
+	}
+	var exponent int
+	for _, b := range eb {
+		exponent = exponent<<8 | int(b)
+	}
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(nb),
+		E: exponent,
+	}, nil
+}
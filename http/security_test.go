@@ -0,0 +1,233 @@
+package http
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func startJWKS(t *testing.T, kid string, key *rsa.PublicKey) *httptest.Server {
+	t.Helper()
+	n := base64.RawURLEncoding.EncodeToString(key.N.Bytes())
+	e := base64.RawURLEncoding.EncodeToString(bigEndianExponent(key.E))
+	body := fmt.Sprintf(`{"keys":[{"kid":%q,"n":%q,"e":%q}]}`, kid, n, e)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+}
+
+func bigEndianExponent(e int) []byte {
+	b := []byte{byte(e >> 16), byte(e >> 8), byte(e)}
+	i := 0
+	for i < len(b)-1 && b[i] == 0 {
+		i++
+	}
+	return b[i:]
+}
+
+func signRS256(t *testing.T, key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	t.Helper()
+	header := map[string]interface{}{"alg": "RS256", "kid": kid, "typ": "JWT"}
+	hb, err := json.Marshal(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cb, err := json.Marshal(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signingInput := base64.RawURLEncoding.EncodeToString(hb) + "." + base64.RawURLEncoding.EncodeToString(cb)
+	h := sha256.Sum256([]byte(signingInput))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, h[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func TestJWKSValidateRejectsForgedSignature(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forgedKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := startJWKS(t, "kid-1", &key.PublicKey)
+	defer srv.Close()
+
+	ks := newJWKS(srv.URL, time.Hour)
+
+	// Token signed with a key that is NOT the one published at JWKSURL
+	// but using the same "kid": must be rejected.
+	forged := signRS256(t, forgedKey, "kid-1", map[string]interface{}{
+		"sub": "attacker",
+		"exp": float64(time.Now().Add(time.Hour).Unix()),
+	})
+	if _, err := ks.validate(forged, "", ""); err == nil {
+		t.Fatal("expected forged token signature to be rejected, got nil error")
+	}
+}
+
+func TestJWKSValidateAcceptsValidSignature(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := startJWKS(t, "kid-1", &key.PublicKey)
+	defer srv.Close()
+
+	ks := newJWKS(srv.URL, time.Hour)
+
+	token := signRS256(t, key, "kid-1", map[string]interface{}{
+		"sub": "user-1",
+		"exp": float64(time.Now().Add(time.Hour).Unix()),
+	})
+	claims, err := ks.validate(token, "", "")
+	if err != nil {
+		t.Fatalf("expected valid token to be accepted, got %v", err)
+	}
+	if claims["sub"] != "user-1" {
+		t.Fatalf("expected sub claim %q, got %v", "user-1", claims["sub"])
+	}
+}
+
+func TestNewJWTMiddlewareInjectsClaims(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := startJWKS(t, "kid-1", &key.PublicKey)
+	defer srv.Close()
+
+	auth := NewJWTMiddleware(JWTMiddlewareConfig{JWKSURL: srv.URL})
+	token := signRS256(t, key, "kid-1", map[string]interface{}{
+		"sub": "user-1",
+		"exp": float64(time.Now().Add(time.Hour).Unix()),
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	ctx, err := auth(context.Background(), req)
+	if err != nil {
+		t.Fatalf("expected a valid token to be accepted, got %v", err)
+	}
+	claims, ok := ClaimsFromContext(ctx)
+	if !ok || claims["sub"] != "user-1" {
+		t.Fatalf("expected injected claims with sub %q, got %v", "user-1", claims)
+	}
+}
+
+func TestNewJWTMiddlewareRejectsMissingBearerToken(t *testing.T) {
+	auth := NewJWTMiddleware(JWTMiddlewareConfig{JWKSURL: "http://unused"})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if _, err := auth(context.Background(), req); err == nil {
+		t.Fatal("expected an error for a request with no Authorization header")
+	}
+}
+
+func TestNewAPIKeyMiddlewareExtractsKeyFromEachLocation(t *testing.T) {
+	cases := []struct {
+		in  string
+		req func() *http.Request
+	}{
+		{"header", func() *http.Request {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("api_key", "secret")
+			return r
+		}},
+		{"query", func() *http.Request {
+			return httptest.NewRequest(http.MethodGet, "/?api_key=secret", nil)
+		}},
+		{"cookie", func() *http.Request {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.AddCookie(&http.Cookie{Name: "api_key", Value: "secret"})
+			return r
+		}},
+	}
+	for _, c := range cases {
+		var got string
+		auth := NewAPIKeyMiddleware(APIKeyMiddlewareConfig{
+			In:   c.in,
+			Name: "api_key",
+			Validate: func(ctx context.Context, key string) (context.Context, error) {
+				got = key
+				return ctx, nil
+			},
+		})
+		if _, err := auth(context.Background(), c.req()); err != nil {
+			t.Fatalf("%s: unexpected error: %v", c.in, err)
+		}
+		if got != "secret" {
+			t.Fatalf("%s: expected extracted key %q, got %q", c.in, "secret", got)
+		}
+	}
+}
+
+func TestNewAPIKeyMiddlewareRejectsMissingKey(t *testing.T) {
+	auth := NewAPIKeyMiddleware(APIKeyMiddlewareConfig{
+		In:   "header",
+		Name: "X-API-Key",
+		Validate: func(ctx context.Context, key string) (context.Context, error) {
+			return ctx, nil
+		},
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	if _, err := auth(context.Background(), req); err == nil {
+		t.Fatal("expected an error for a request with no API key")
+	}
+}
+
+func TestNewBasicAuthMiddlewareDelegatesToValidate(t *testing.T) {
+	auth := NewBasicAuthMiddleware(BasicAuthMiddlewareConfig{
+		Validate: func(ctx context.Context, user, pass string) (context.Context, error) {
+			if user != "alice" || pass != "s3cret" {
+				return nil, errors.New("invalid credentials")
+			}
+			return ctx, nil
+		},
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.SetBasicAuth("alice", "s3cret")
+	if _, err := auth(context.Background(), req); err != nil {
+		t.Fatalf("expected valid credentials to be accepted, got %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	if _, err := auth(context.Background(), req); err == nil {
+		t.Fatal("expected an error for a request with no basic auth credentials")
+	}
+}
+
+func TestNewOAuth2MiddlewareDelegatesToValidate(t *testing.T) {
+	auth := NewOAuth2Middleware(OAuth2MiddlewareConfig{
+		Validate: func(ctx context.Context, token string) (context.Context, error) {
+			if token != "valid-token" {
+				return nil, errors.New("invalid token")
+			}
+			return ctx, nil
+		},
+	})
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer valid-token")
+	if _, err := auth(context.Background(), req); err != nil {
+		t.Fatalf("expected a valid token to be accepted, got %v", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer wrong-token")
+	if _, err := auth(context.Background(), req); err == nil {
+		t.Fatal("expected an error for an invalid token")
+	}
+}
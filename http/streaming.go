@@ -0,0 +1,458 @@
+package http
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// SSEEvent is a single Server-Sent Events message.
+	SSEEvent struct {
+		// ID is the event "id" field, used by clients to resume the
+		// stream via the Last-Event-ID header.
+		ID string
+		// Name is the event "event" field, empty means the default
+		// "message" event.
+		Name string
+		// Retry is the event "retry" field, in milliseconds, 0 means
+		// the field is omitted.
+		Retry int
+		// Data is the value marshaled as JSON into the event "data"
+		// field.
+		Data interface{}
+	}
+
+	// SSEWriter streams SSEEvent values to a client over an HTTP
+	// response, flushing after every event so results are pushed as
+	// they become available instead of being buffered.
+	SSEWriter struct {
+		w       http.ResponseWriter
+		flusher http.Flusher
+	}
+)
+
+// NewSSEWriter prepares w's response to stream Server-Sent Events and
+// returns an SSEWriter used to send them. It fails (returning nil) if w
+// doesn't support flushing, which is required to push events to the client
+// as they are produced.
+func NewSSEWriter(w http.ResponseWriter) *SSEWriter {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil
+	}
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	return &SSEWriter{w: w, flusher: flusher}
+}
+
+// LastEventID returns the value of the incoming Last-Event-ID header, used
+// by a resuming client to indicate where the stream should continue from.
+func LastEventID(r *http.Request) string {
+	return r.Header.Get("Last-Event-ID")
+}
+
+// Send writes a single event to the stream and flushes it to the client.
+func (s *SSEWriter) Send(e SSEEvent) error {
+	var b strings.Builder
+	if e.ID != "" {
+		fmt.Fprintf(&b, "id: %s\n", e.ID)
+	}
+	if e.Name != "" {
+		fmt.Fprintf(&b, "event: %s\n", e.Name)
+	}
+	if e.Retry > 0 {
+		fmt.Fprintf(&b, "retry: %d\n", e.Retry)
+	}
+	data, err := json.Marshal(e.Data)
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fmt.Fprintf(&b, "data: %s\n", line)
+	}
+	b.WriteString("\n")
+	if _, err := s.w.Write([]byte(b.String())); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+type (
+	// WebSocketConfig configures UpgradeWebSocket and NewStream.
+	WebSocketConfig struct {
+		// Subprotocols lists the subprotocols the server accepts, in
+		// preference order. UpgradeWebSocket picks the first one also
+		// present in the client's Sec-WebSocket-Protocol header.
+		Subprotocols []string
+		// PingInterval is how often UpgradeWebSocket sends a ping
+		// control frame to keep the connection alive, e.g. "30s". Empty
+		// or unparseable disables keepalive pings.
+		PingInterval string
+		// MaxMessageSize is the largest message, in bytes, accepted
+		// from the client, 0 means no limit.
+		MaxMessageSize int
+		// Binary frames messages as binary instead of JSON text.
+		Binary bool
+	}
+
+	// Stream is a typed bidirectional WebSocket stream exposed to an
+	// endpoint implementation. Send marshals v (as JSON, or raw bytes
+	// when cfg.Binary is set) into a single outgoing message; Recv
+	// unmarshals the next incoming message into v.
+	Stream interface {
+		Send(v interface{}) error
+		Recv(v interface{}) error
+		Close() error
+	}
+
+	// wsConn is the subset of a WebSocket connection implementation
+	// (e.g. gorilla/websocket.Conn) that Stream needs. UpgradeWebSocket
+	// supplies a wsFrameConn, configured from WebSocketConfig, as the
+	// concrete connection.
+	wsConn interface {
+		ReadMessage() (messageType int, p []byte, err error)
+		WriteMessage(messageType int, data []byte) error
+		Close() error
+	}
+
+	wsStream struct {
+		conn   wsConn
+		binary bool
+	}
+)
+
+// Binary and text WebSocket op codes, mirroring the RFC 6455 constants so
+// this file has no hard dependency on a specific WebSocket library.
+const (
+	wsTextMessage   = 1
+	wsBinaryMessage = 2
+)
+
+// NewStream wraps conn, an established WebSocket connection, into a typed
+// Stream that marshals/unmarshals messages as JSON or raw binary according
+// to cfg.Binary.
+func NewStream(conn wsConn, cfg WebSocketConfig) Stream {
+	return &wsStream{conn: conn, binary: cfg.Binary}
+}
+
+func (s *wsStream) Send(v interface{}) error {
+	if s.binary {
+		b, ok := v.([]byte)
+		if !ok {
+			return fmt.Errorf("binary stream expects []byte, got %T", v)
+		}
+		return s.conn.WriteMessage(wsBinaryMessage, b)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.conn.WriteMessage(wsTextMessage, b)
+}
+
+func (s *wsStream) Recv(v interface{}) error {
+	_, p, err := s.conn.ReadMessage()
+	if err != nil {
+		return err
+	}
+	if s.binary {
+		b, ok := v.(*[]byte)
+		if !ok {
+			return fmt.Errorf("binary stream expects *[]byte, got %T", v)
+		}
+		*b = p
+		return nil
+	}
+	return json.Unmarshal(p, v)
+}
+
+func (s *wsStream) Close() error { return s.conn.Close() }
+
+// flushWriter is an io.Writer that flushes both its buffered writer and the
+// underlying http.Flusher after every write, so data written to an
+// http.ResponseWriter reaches the client immediately. Nothing in this
+// package or in the generated handlers uses it.
+type flushWriter struct {
+	w *bufio.Writer
+	f http.Flusher
+}
+
+func (fw flushWriter) Write(p []byte) (int, error) {
+	n, err := fw.w.Write(p)
+	if err != nil {
+		return n, err
+	}
+	if err := fw.w.Flush(); err != nil {
+		return n, err
+	}
+	fw.f.Flush()
+	return n, nil
+}
+
+// websocketGUID is the magic value RFC 6455 section 1.3 appends to a
+// client's Sec-WebSocket-Key before hashing it to compute the
+// Sec-WebSocket-Accept response header.
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// RFC 6455 section 5.2 control and data frame opcodes. wsTextMessage and
+// wsBinaryMessage double as the Stream message types returned by
+// ReadMessage/expected by WriteMessage.
+const (
+	wsOpClose = 0x8
+	wsOpPing  = 0x9
+	wsOpPong  = 0xA
+)
+
+// UpgradeWebSocket performs the RFC 6455 handshake on r, hijacking its
+// underlying connection, and returns a Stream that frames messages
+// according to cfg. It picks the first of cfg.Subprotocols also offered by
+// the client's Sec-WebSocket-Protocol header (or none), and, when
+// cfg.PingInterval parses as a positive duration, starts a goroutine that
+// pings the client at that interval to keep the connection alive.
+func UpgradeWebSocket(w http.ResponseWriter, r *http.Request, cfg WebSocketConfig) (Stream, error) {
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+		return nil, fmt.Errorf("websocket: missing or invalid Upgrade header")
+	}
+	if !headerContainsToken(r.Header.Get("Connection"), "upgrade") {
+		return nil, fmt.Errorf("websocket: missing or invalid Connection header")
+	}
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" {
+		return nil, fmt.Errorf("websocket: missing Sec-WebSocket-Key header")
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		return nil, fmt.Errorf("websocket: response writer does not support hijacking")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	proto := selectSubprotocol(cfg.Subprotocols, r.Header.Get("Sec-WebSocket-Protocol"))
+	var resp strings.Builder
+	resp.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+	resp.WriteString("Upgrade: websocket\r\n")
+	resp.WriteString("Connection: Upgrade\r\n")
+	fmt.Fprintf(&resp, "Sec-WebSocket-Accept: %s\r\n", websocketAcceptKey(key))
+	if proto != "" {
+		fmt.Fprintf(&resp, "Sec-WebSocket-Protocol: %s\r\n", proto)
+	}
+	resp.WriteString("\r\n")
+	if _, err := rw.WriteString(resp.String()); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	wc := &wsFrameConn{conn: conn, rw: rw, maxMessageSize: cfg.MaxMessageSize}
+	if d, err := time.ParseDuration(cfg.PingInterval); err == nil && d > 0 {
+		go wc.pingLoop(d)
+	}
+	return NewStream(wc, cfg), nil
+}
+
+// websocketAcceptKey computes the Sec-WebSocket-Accept header value for the
+// client-supplied Sec-WebSocket-Key per RFC 6455 section 4.2.2.
+func websocketAcceptKey(key string) string {
+	h := sha1.New()
+	h.Write([]byte(key))
+	h.Write([]byte(websocketGUID))
+	return base64.StdEncoding.EncodeToString(h.Sum(nil))
+}
+
+// headerContainsToken reports whether the comma-separated header value
+// contains token, ignoring case and surrounding whitespace.
+func headerContainsToken(header, token string) bool {
+	for _, part := range strings.Split(header, ",") {
+		if strings.EqualFold(strings.TrimSpace(part), token) {
+			return true
+		}
+	}
+	return false
+}
+
+// selectSubprotocol returns the first entry of server, in server's
+// preference order, that also appears in the client's comma-separated
+// Sec-WebSocket-Protocol header value, or "" if none match or server is
+// empty.
+func selectSubprotocol(server []string, clientHeader string) string {
+	if clientHeader == "" {
+		return ""
+	}
+	offered := map[string]bool{}
+	for _, p := range strings.Split(clientHeader, ",") {
+		offered[strings.TrimSpace(p)] = true
+	}
+	for _, p := range server {
+		if offered[p] {
+			return p
+		}
+	}
+	return ""
+}
+
+// wsFrameConn implements wsConn directly over a hijacked net.Conn using the
+// RFC 6455 framing format, so the runtime has no hard dependency on a
+// specific WebSocket library. It answers ping control frames with pong,
+// drops incoming pong frames, and enforces maxMessageSize.
+type wsFrameConn struct {
+	conn           net.Conn
+	rw             *bufio.ReadWriter
+	maxMessageSize int
+	writeMu        sync.Mutex
+}
+
+// ReadMessage reads and reassembles the next complete data message,
+// transparently answering ping frames with pong and skipping pong frames,
+// until a text or binary message is available or an error occurs.
+func (c *wsFrameConn) ReadMessage() (int, []byte, error) {
+	var (
+		messageType int
+		payload     []byte
+	)
+	for {
+		fin, opcode, data, err := c.readFrame()
+		if err != nil {
+			return 0, nil, err
+		}
+		switch opcode {
+		case wsOpPing:
+			if err := c.writeFrame(true, wsOpPong, data); err != nil {
+				return 0, nil, err
+			}
+			continue
+		case wsOpPong:
+			continue
+		case wsOpClose:
+			c.Close()
+			return 0, nil, io.EOF
+		case wsTextMessage, wsBinaryMessage:
+			messageType = opcode
+			payload = append(payload[:0:0], data...)
+		default: // continuation frame
+			payload = append(payload, data...)
+		}
+		if c.maxMessageSize > 0 && len(payload) > c.maxMessageSize {
+			return 0, nil, fmt.Errorf("websocket: message exceeds max size of %d bytes", c.maxMessageSize)
+		}
+		if fin {
+			return messageType, payload, nil
+		}
+	}
+}
+
+// readFrame reads a single RFC 6455 frame, unmasking the payload (client
+// frames are always masked per section 5.1).
+func (c *wsFrameConn) readFrame() (fin bool, opcode int, payload []byte, err error) {
+	head := make([]byte, 2)
+	if _, err = io.ReadFull(c.rw, head); err != nil {
+		return false, 0, nil, err
+	}
+	fin = head[0]&0x80 != 0
+	opcode = int(head[0] & 0x0f)
+	masked := head[1]&0x80 != 0
+	length := int64(head[1] & 0x7f)
+	switch length {
+	case 126:
+		ext := make([]byte, 2)
+		if _, err = io.ReadFull(c.rw, ext); err != nil {
+			return false, 0, nil, err
+		}
+		length = int64(binary.BigEndian.Uint16(ext))
+	case 127:
+		ext := make([]byte, 8)
+		if _, err = io.ReadFull(c.rw, ext); err != nil {
+			return false, 0, nil, err
+		}
+		length = int64(binary.BigEndian.Uint64(ext))
+	}
+	if c.maxMessageSize > 0 && length > int64(c.maxMessageSize) {
+		return false, 0, nil, fmt.Errorf("websocket: message exceeds max size of %d bytes", c.maxMessageSize)
+	}
+	var mask [4]byte
+	if masked {
+		if _, err = io.ReadFull(c.rw, mask[:]); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload = make([]byte, length)
+	if _, err = io.ReadFull(c.rw, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, opcode, payload, nil
+}
+
+// WriteMessage sends data as a single, unmasked, final frame of the given
+// opcode (server-to-client frames are never masked per section 5.1).
+func (c *wsFrameConn) WriteMessage(messageType int, data []byte) error {
+	return c.writeFrame(true, messageType, data)
+}
+
+func (c *wsFrameConn) writeFrame(fin bool, opcode int, data []byte) error {
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+
+	var head []byte
+	first := byte(opcode)
+	if fin {
+		first |= 0x80
+	}
+	n := len(data)
+	switch {
+	case n <= 125:
+		head = []byte{first, byte(n)}
+	case n <= 0xffff:
+		head = make([]byte, 4)
+		head[0], head[1] = first, 126
+		binary.BigEndian.PutUint16(head[2:], uint16(n))
+	default:
+		head = make([]byte, 10)
+		head[0], head[1] = first, 127
+		binary.BigEndian.PutUint64(head[2:], uint64(n))
+	}
+	if _, err := c.rw.Write(head); err != nil {
+		return err
+	}
+	if _, err := c.rw.Write(data); err != nil {
+		return err
+	}
+	return c.rw.Flush()
+}
+
+// pingLoop sends a ping control frame every interval until a write fails,
+// e.g. because the connection was closed.
+func (c *wsFrameConn) pingLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		if err := c.writeFrame(true, wsOpPing, nil); err != nil {
+			return
+		}
+	}
+}
+
+func (c *wsFrameConn) Close() error { return c.conn.Close() }
@@ -0,0 +1,163 @@
+package http
+
+import (
+	"bufio"
+	"encoding/binary"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// dialWebSocket performs the client side of the RFC 6455 handshake against
+// addr and returns the raw connection for the test to frame messages over.
+func dialWebSocket(t *testing.T, addr string) net.Conn {
+	t.Helper()
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := "GET / HTTP/1.1\r\n" +
+		"Host: " + addr + "\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n" +
+		"Sec-WebSocket-Version: 13\r\n\r\n"
+	if _, err := conn.Write([]byte(req)); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("expected 101 Switching Protocols, got %d", resp.StatusCode)
+	}
+	return conn
+}
+
+// writeClientFrame writes a single masked (as RFC 6455 requires for
+// client-to-server frames) data frame.
+func writeClientFrame(t *testing.T, conn net.Conn, opcode int, data []byte) {
+	t.Helper()
+	mask := [4]byte{0x12, 0x34, 0x56, 0x78}
+	masked := make([]byte, len(data))
+	for i, b := range data {
+		masked[i] = b ^ mask[i%4]
+	}
+	var frame []byte
+	frame = append(frame, byte(0x80|opcode))
+	switch {
+	case len(masked) <= 125:
+		frame = append(frame, byte(0x80|len(masked)))
+	default:
+		ext := make([]byte, 2)
+		binary.BigEndian.PutUint16(ext, uint16(len(masked)))
+		frame = append(frame, 0x80|126)
+		frame = append(frame, ext...)
+	}
+	frame = append(frame, mask[:]...)
+	frame = append(frame, masked...)
+	if _, err := conn.Write(frame); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestUpgradeWebSocketEchoesTextMessage(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stream, err := UpgradeWebSocket(w, r, WebSocketConfig{})
+		if err != nil {
+			t.Errorf("UpgradeWebSocket failed: %v", err)
+			return
+		}
+		var msg string
+		if err := stream.Recv(&msg); err != nil {
+			t.Errorf("Recv failed: %v", err)
+			return
+		}
+		if err := stream.Send(msg); err != nil {
+			t.Errorf("Send failed: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	addr := srv.Listener.Addr().String()
+	conn := dialWebSocket(t, addr)
+	defer conn.Close()
+
+	writeClientFrame(t, conn, 0x1, []byte(`"hello"`))
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	head := make([]byte, 2)
+	if _, err := readFull(conn, head); err != nil {
+		t.Fatal(err)
+	}
+	if head[0]&0x0f != 0x1 {
+		t.Fatalf("expected a text frame opcode, got %x", head[0]&0x0f)
+	}
+	n := int(head[1] & 0x7f)
+	payload := make([]byte, n)
+	if _, err := readFull(conn, payload); err != nil {
+		t.Fatal(err)
+	}
+	if string(payload) != `"hello"` {
+		t.Fatalf("expected echoed payload %q, got %q", `"hello"`, payload)
+	}
+}
+
+func TestUpgradeWebSocketRejectsOversizedFrameBeforeReadingPayload(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stream, err := UpgradeWebSocket(w, r, WebSocketConfig{MaxMessageSize: 4})
+		if err != nil {
+			t.Errorf("UpgradeWebSocket failed: %v", err)
+			return
+		}
+		var msg string
+		if err := stream.Recv(&msg); err == nil {
+			t.Error("expected Recv to fail for a frame declaring a size over MaxMessageSize")
+		}
+		stream.Close()
+	}))
+	defer srv.Close()
+
+	addr := srv.Listener.Addr().String()
+	conn := dialWebSocket(t, addr)
+	defer conn.Close()
+
+	// Declare a 10-byte payload, which exceeds MaxMessageSize, but never
+	// send it. If the server only enforced MaxMessageSize after reading
+	// the payload, it would block here waiting for bytes that never
+	// arrive instead of rejecting the frame as soon as the length is read.
+	mask := [4]byte{0x12, 0x34, 0x56, 0x78}
+	frame := []byte{0x81, 0x80 | 10, mask[0], mask[1], mask[2], mask[3]}
+	if _, err := conn.Write(frame); err != nil {
+		t.Fatal(err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 1)
+	if _, err := conn.Read(buf); err == nil {
+		t.Fatal("expected the connection to be closed after the oversized frame")
+	}
+}
+
+func TestUpgradeWebSocketRejectsMissingUpgradeHeader(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	rec := httptest.NewRecorder()
+	if _, err := UpgradeWebSocket(rec, req, WebSocketConfig{}); err == nil {
+		t.Fatal("expected an error for a request missing the Upgrade header")
+	}
+}
+
+func readFull(conn net.Conn, buf []byte) (int, error) {
+	total := 0
+	for total < len(buf) {
+		n, err := conn.Read(buf[total:])
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}